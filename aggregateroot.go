@@ -3,7 +3,6 @@ package eventsourcing
 import (
 	"errors"
 	"reflect"
-	"time"
 
 	"github.com/hallgren/eventsourcing/core"
 )
@@ -46,7 +45,7 @@ func (ar *AggregateRoot) TrackChangeWithMetadata(a aggregate, data interface{},
 			AggregateID:   ar.aggregateID,
 			Version:       ar.nextVersion(),
 			AggregateType: aggregateType(a),
-			Timestamp:     time.Now().UTC(),
+			Timestamp:     clock.Now().UTC(),
 		},
 		data:     data,
 		metadata: metadata,
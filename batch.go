@@ -0,0 +1,67 @@
+package eventsourcing
+
+import (
+	"context"
+
+	"github.com/hallgren/eventsourcing/core"
+)
+
+// Batch holds the events handled in one call to a batch projection callback
+type Batch struct {
+	Events         []Event
+	CheckpointName string  // CheckpointName is the name the projection checkpoint is stored by
+	Position       Version // Position is the global version the projection advances to when the batch is handled
+}
+
+// runBatch collects the events into batches that are handled by the batch callback. A batch is handled when it
+// reaches the batch size, when the batch timeout is reached or when there are no more events in the iterator.
+func (p *Projection) runBatch(ctx context.Context, iterator core.Iterator) (bool, Event, error) {
+	var ran bool
+	var lastHandledEvent Event
+
+	position := core.Version(p.Position())
+	events := make([]Event, 0)
+	start := p.clock().Now()
+
+	flush := func() error {
+		if len(events) > 0 {
+			batch := Batch{Events: events, CheckpointName: p.Key(), Position: Version(position)}
+			err := p.retry(ctx, func() error {
+				return p.batchCallbackF(batch)
+			})
+			if err != nil {
+				return err
+			}
+			lastHandledEvent = events[len(events)-1]
+		}
+		p.setPosition(position)
+		events = make([]Event, 0)
+		start = p.clock().Now()
+		return nil
+	}
+
+	for iterator.Next() {
+		ran = true
+		event, err := iterator.Value()
+		if err != nil {
+			return ran, lastHandledEvent, err
+		}
+
+		e, found, err := p.decode(event)
+		if err != nil {
+			return ran, lastHandledEvent, err
+		}
+		position = event.GlobalVersion
+		if found {
+			events = append(events, e)
+		}
+
+		if len(events) >= p.BatchSize || (p.BatchTimeout > 0 && p.clock().Now().Sub(start) >= p.BatchTimeout) {
+			err = flush()
+			if err != nil {
+				return ran, lastHandledEvent, err
+			}
+		}
+	}
+	return ran, lastHandledEvent, flush()
+}
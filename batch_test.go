@@ -0,0 +1,134 @@
+package eventsourcing_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hallgren/eventsourcing"
+	checkpoint "github.com/hallgren/eventsourcing/checkpointstore/memory"
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/eventstore/memory"
+)
+
+func TestBatchProjection(t *testing.T) {
+	// setup
+	es := memory.Create()
+	register := eventsourcing.NewRegister()
+	register.Register(&Person{})
+
+	err := createPersonEvent(es, "kalle", 11)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sizes := make([]int, 0)
+	p := eventsourcing.NewProjectionHandler(register, eventsourcing.EncoderJSON{})
+	proj := p.BatchProjection(es.AllFrom(100), func(batch eventsourcing.Batch) error {
+		sizes = append(sizes, len(batch.Events))
+		if batch.Position != batch.Events[len(batch.Events)-1].GlobalVersion() {
+			t.Fatalf("expected batch position %d to be the global version of the last event", batch.Position)
+		}
+		return nil
+	})
+	proj.BatchSize = 5
+
+	result := proj.RunToEnd(context.Background())
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	if len(sizes) != 3 || sizes[0] != 5 || sizes[1] != 5 || sizes[2] != 2 {
+		t.Fatalf("expected batches of size 5, 5 and 2 got %v", sizes)
+	}
+	if result.LastHandledEvent.GlobalVersion() != 12 {
+		t.Fatalf("expected last handled event to be 12 got %d", result.LastHandledEvent.GlobalVersion())
+	}
+}
+
+func TestBatchProjectionError(t *testing.T) {
+	// setup
+	es := memory.Create()
+	register := eventsourcing.NewRegister()
+	register.Register(&Person{})
+
+	err := createPersonEvent(es, "kalle", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ErrApplication := errors.New("application error")
+	p := eventsourcing.NewProjectionHandler(register, eventsourcing.EncoderJSON{})
+	proj := p.BatchProjection(es.AllFrom(100), func(batch eventsourcing.Batch) error {
+		if batch.Position > 4 {
+			return ErrApplication
+		}
+		return nil
+	})
+	proj.BatchSize = 2
+
+	_, result := proj.RunOnce()
+	if !errors.Is(result.Error, ErrApplication) {
+		t.Fatalf("expected application error got %v", result.Error)
+	}
+	// none of the events in the failing batch is handled
+	if proj.Position() != 4 {
+		t.Fatalf("expected position 4 got %d", proj.Position())
+	}
+}
+
+func TestBatchProjectionCallbackCheckpoint(t *testing.T) {
+	// setup
+	es := memory.Create()
+	register := eventsourcing.NewRegister()
+	register.Register(&Person{})
+	checkpoints := checkpoint.Create()
+
+	err := createPersonEvent(es, "kalle", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handled := 0
+	callbackF := func(batch eventsourcing.Batch) error {
+		handled += len(batch.Events)
+		// store the checkpoint as part of the batch, could be in the same transaction as the read-model
+		return checkpoints.Save(context.Background(), batch.CheckpointName, core.Version(batch.Position))
+	}
+
+	p := eventsourcing.NewProjectionHandler(register, eventsourcing.EncoderJSON{})
+	proj := p.BatchProjection(es.AllFrom(100), callbackF)
+	proj.Name = "person"
+	proj.Checkpoint = checkpoints
+	proj.CallbackCheckpoint = true
+
+	result := proj.RunToEnd(context.Background())
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+
+	err = createPersonEvent(es, "anka", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a restarted projection continues from the checkpoint stored by the callback
+	proj = p.BatchProjection(es.AllFrom(100), callbackF)
+	proj.Name = "person"
+	proj.Checkpoint = checkpoints
+	proj.CallbackCheckpoint = true
+
+	result = proj.RunToEnd(context.Background())
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	if handled != 8 {
+		t.Fatalf("expected 8 handled events got %d", handled)
+	}
+	position, err := checkpoints.Get(context.Background(), "person")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if position != 8 {
+		t.Fatalf("expected checkpoint 8 got %d", position)
+	}
+}
@@ -0,0 +1,19 @@
+package eventsourcing
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hallgren/eventsourcing/core"
+)
+
+// ErrCheckpointNotFound returned when no checkpoint is found in the checkpoint store
+var ErrCheckpointNotFound = errors.New("checkpoint not found")
+
+// CheckpointStore expose the methods a checkpoint store must uphold.
+// A checkpoint is the last global version a named projection has handled.
+type CheckpointStore interface {
+	Save(ctx context.Context, name string, position core.Version) error
+	Get(ctx context.Context, name string) (core.Version, error)
+	Delete(ctx context.Context, name string) error
+}
@@ -0,0 +1,92 @@
+package bbolt
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"time"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/core"
+	"go.etcd.io/bbolt"
+)
+
+const (
+	checkpointBucketName = "checkpoints"
+)
+
+// BBolt is the checkpoint store handler
+type BBolt struct {
+	db *bbolt.DB
+}
+
+// MustOpenBBolt opens the checkpoint store found in the given file. If the file is not found it will be created and
+// initialized. Will panic if it has problems persisting the changes to the filesystem.
+func MustOpenBBolt(dbFile string) *BBolt {
+	db, err := bbolt.Open(dbFile, 0600, &bbolt.Options{
+		Timeout: 1 * time.Second,
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	// Ensure that we have a bucket to store the checkpoints
+	err = db.Update(func(tx *bbolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists([]byte(checkpointBucketName)); err != nil {
+			return errors.New("could not create checkpoint bucket")
+		}
+		return nil
+	})
+	if err != nil {
+		panic(err)
+	}
+	return &BBolt{
+		db: db,
+	}
+}
+
+// Save persists the checkpoint
+func (b *BBolt) Save(ctx context.Context, name string, position core.Version) error {
+	return b.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(checkpointBucketName))
+		return bucket.Put([]byte(name), itob(uint64(position)))
+	})
+}
+
+// Get returns the checkpoint
+func (b *BBolt) Get(ctx context.Context, name string) (core.Version, error) {
+	var position core.Version
+	err := b.db.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(checkpointBucketName))
+		value := bucket.Get([]byte(name))
+		if value == nil {
+			return eventsourcing.ErrCheckpointNotFound
+		}
+		position = core.Version(binary.BigEndian.Uint64(value))
+		return nil
+	})
+	return position, err
+}
+
+// Delete removes the checkpoint
+func (b *BBolt) Delete(ctx context.Context, name string) error {
+	return b.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(checkpointBucketName))
+		if bucket.Get([]byte(name)) == nil {
+			return eventsourcing.ErrCheckpointNotFound
+		}
+		return bucket.Delete([]byte(name))
+	})
+}
+
+// Close closes the underlying database
+func (b *BBolt) Close() error {
+	return b.db.Close()
+}
+
+// itob returns an 8-byte big endian representation of v.
+func itob(v uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, v)
+	return b
+}
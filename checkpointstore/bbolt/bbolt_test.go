@@ -0,0 +1,22 @@
+package bbolt_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/checkpointstore/bbolt"
+	"github.com/hallgren/eventsourcing/eventsourcingtest"
+)
+
+func TestSuite(t *testing.T) {
+	f := func() (eventsourcing.CheckpointStore, func(), error) {
+		dbFile := "checkpoints.db"
+		cs := bbolt.MustOpenBBolt(dbFile)
+		return cs, func() {
+			cs.Close()
+			os.Remove(dbFile)
+		}, nil
+	}
+	eventsourcingtest.TestCheckpointStore(t, f)
+}
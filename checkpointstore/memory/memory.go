@@ -0,0 +1,55 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/core"
+)
+
+type Memory struct {
+	checkpoints map[string]core.Version
+	lock        sync.Mutex
+}
+
+// Create in memory checkpoint store
+func Create() *Memory {
+	return &Memory{
+		checkpoints: make(map[string]core.Version),
+	}
+}
+
+func (m *Memory) Close() {
+
+}
+
+func (m *Memory) Get(ctx context.Context, name string) (core.Version, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	position, ok := m.checkpoints[name]
+	if !ok {
+		return 0, eventsourcing.ErrCheckpointNotFound
+	}
+	return position, nil
+}
+
+func (m *Memory) Save(ctx context.Context, name string, position core.Version) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.checkpoints[name] = position
+	return nil
+}
+
+func (m *Memory) Delete(ctx context.Context, name string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if _, ok := m.checkpoints[name]; !ok {
+		return eventsourcing.ErrCheckpointNotFound
+	}
+	delete(m.checkpoints, name)
+	return nil
+}
@@ -0,0 +1,17 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/checkpointstore/memory"
+	"github.com/hallgren/eventsourcing/eventsourcingtest"
+)
+
+func TestSuite(t *testing.T) {
+	f := func() (eventsourcing.CheckpointStore, func(), error) {
+		cs := memory.Create()
+		return cs, func() { cs.Close() }, nil
+	}
+	eventsourcingtest.TestCheckpointStore(t, f)
+}
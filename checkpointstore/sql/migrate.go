@@ -0,0 +1,36 @@
+package sql
+
+import "context"
+
+const createTable = `create table checkpoints (name VARCHAR(255) NOT NULL PRIMARY KEY, position INTEGER NOT NULL);`
+
+// Migrate the database
+func (s *SQL) Migrate() error {
+	sqlStmt := []string{
+		createTable,
+	}
+	return s.migrate(sqlStmt)
+}
+
+func (s *SQL) migrate(stm []string) error {
+	tx, err := s.db.BeginTx(context.Background(), nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// check if the migration is already done
+	rows, err := tx.Query(`Select count(*) from checkpoints`)
+	if err == nil {
+		rows.Close()
+		return nil
+	}
+
+	for _, b := range stm {
+		_, err := tx.Exec(b)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
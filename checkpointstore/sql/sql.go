@@ -0,0 +1,93 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/core"
+)
+
+type SQL struct {
+	db *sql.DB
+}
+
+// Open connection to database
+func Open(db *sql.DB) *SQL {
+	return &SQL{
+		db: db,
+	}
+}
+
+// Close the connection
+func (s *SQL) Close() {
+	s.db.Close()
+}
+
+// Save persists the checkpoint
+func (s *SQL) Save(ctx context.Context, name string, position core.Version) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.New(fmt.Sprintf("could not start a write transaction, %v", err))
+	}
+	defer tx.Rollback()
+
+	err = SaveTx(ctx, tx, name, position)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// SaveTx persists the checkpoint within a transaction owned by the caller. It makes it possible to
+// update a read-model and the checkpoint in the same transaction.
+func SaveTx(ctx context.Context, tx *sql.Tx, name string, position core.Version) error {
+	statement := `UPDATE checkpoints set position=$1 where name=$2`
+	res, err := tx.ExecContext(ctx, statement, position, name)
+	if err != nil {
+		return err
+	}
+	updated, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if updated > 0 {
+		return nil
+	}
+	// no previous checkpoint
+	statement = `INSERT INTO checkpoints (name, position) VALUES ($1, $2)`
+	_, err = tx.ExecContext(ctx, statement, name, position)
+	return err
+}
+
+// Delete removes the checkpoint
+func (s *SQL) Delete(ctx context.Context, name string) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM checkpoints where name=$1`, name)
+	if err != nil {
+		return err
+	}
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return eventsourcing.ErrCheckpointNotFound
+	}
+	return nil
+}
+
+// Get return the checkpoint from the database
+func (s *SQL) Get(ctx context.Context, name string) (core.Version, error) {
+	var position core.Version
+
+	selectStm := `Select position from checkpoints where name=$1`
+	err := s.db.QueryRowContext(ctx, selectStm, name).Scan(&position)
+	if err != nil && errors.Is(err, sql.ErrNoRows) {
+		return 0, eventsourcing.ErrCheckpointNotFound
+	} else if err != nil {
+		return 0, err
+	}
+	return position, nil
+}
@@ -0,0 +1,84 @@
+package sql_test
+
+import (
+	"context"
+	sqldriver "database/sql"
+	"testing"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/checkpointstore/sql"
+	"github.com/hallgren/eventsourcing/eventsourcingtest"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestSuite(t *testing.T) {
+	f := func() (eventsourcing.CheckpointStore, func(), error) {
+		return checkpointstore()
+	}
+	eventsourcingtest.TestCheckpointStore(t, f)
+}
+
+func TestMultipleMigrate(t *testing.T) {
+	cs, close, err := checkpointstore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer close()
+	err = cs.Migrate()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSaveTx(t *testing.T) {
+	db, err := sqldriver.Open("sqlite3", "file::memory:?cache=shared")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	cs := sql.Open(db)
+	defer cs.Close()
+	err = cs.Migrate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = sql.SaveTx(context.Background(), tx, "projection", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the checkpoint should not be stored when the transaction is rolled back
+	tx.Rollback()
+
+	_, err = cs.Get(context.Background(), "projection")
+	if err != eventsourcing.ErrCheckpointNotFound {
+		t.Fatalf("expected ErrCheckpointNotFound got %v", err)
+	}
+}
+
+func checkpointstore() (*sql.SQL, func(), error) {
+	db, err := sqldriver.Open("sqlite3", "file::memory:?cache=shared")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	db.SetMaxOpenConns(1)
+	err = db.Ping()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	store := sql.Open(db)
+	err = store.Migrate()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return store, func() {
+		store.Close()
+	}, nil
+}
@@ -0,0 +1,35 @@
+package eventsourcing
+
+import "time"
+
+// Clock returns the current time and waits for durations. It makes the time dependent parts testable without sleeping.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// clock is the global clock used for event timestamps and as the default clock in projections and the scheduler.
+// It could be changed from the outside via the SetClock function.
+var clock Clock = systemClock{}
+
+// SetClock is used to change the global clock
+// default is the system clock
+func SetClock(c Clock) {
+	clock = c
+}
+
+// systemClock returns the time from the system
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now().UTC()
+}
+
+func (systemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// SystemClock returns the clock based on the system time
+func SystemClock() Clock {
+	return systemClock{}
+}
@@ -0,0 +1,67 @@
+package eventsourcing_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/eventsourcingtest"
+	"github.com/hallgren/eventsourcing/eventstore/memory"
+)
+
+func TestClockTimestamp(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	eventsourcing.SetClock(eventsourcingtest.NewFakeClock(now))
+	defer eventsourcing.SetClock(eventsourcing.SystemClock())
+
+	person, err := CreatePerson("kalle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !person.Events()[0].Timestamp().Equal(now) {
+		t.Fatalf("expected timestamp %v got %v", now, person.Events()[0].Timestamp())
+	}
+}
+
+func TestClockPace(t *testing.T) {
+	es := memory.Create()
+	register := eventsourcing.NewRegister()
+	register.Register(&Person{})
+
+	var handled atomic.Int32
+	p := eventsourcing.NewProjectionHandler(register, eventsourcing.EncoderJSON{})
+	proj := p.ProjectionFrom(es.AllFrom(1), func(event eventsourcing.Event) error {
+		handled.Add(1)
+		return nil
+	})
+	clock := eventsourcingtest.NewFakeClock(time.Now())
+	proj.Clock = clock
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go proj.Run(ctx, time.Hour)
+
+	// wait for the projection to reach the end of the event stream and wait for the pace
+	waitFor(t, func() bool { return clock.Waiters() == 1 })
+	err := createPersonEvent(es, "kalle", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(time.Hour)
+	waitFor(t, func() bool { return handled.Load() == 1 })
+}
+
+// waitFor waits for the condition to be true
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+	timeout := time.After(time.Second)
+	for !condition() {
+		select {
+		case <-timeout:
+			t.Fatal("condition not met")
+		case <-time.After(time.Millisecond):
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package eventsourcing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// ErrCommandNotHandled is returned when dispatching a command without a registered handler
+var ErrCommandNotHandled = errors.New("command not handled")
+
+// CommandIDKey is the metadata key on the events saved by a command dispatched with a command id
+const CommandIDKey = "command_id"
+
+// commandIDKey is the context key of the command id
+type commandIDKey struct{}
+
+// WithCommandID returns a context that dispatches the command with the id. The id is stored in the metadata of the
+// saved events and a command with an id that the target aggregate has already handled is skipped.
+func WithCommandID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, commandIDKey{}, id)
+}
+
+// CommandID returns the command id from the context and false if the command is dispatched without an id
+func CommandID(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(commandIDKey{}).(string)
+	return id, ok && id != ""
+}
+
+// CommandResult holds the outcome of a handled command
+type CommandResult struct {
+	AggregateID   string  // AggregateID is the id of the aggregate that handled the command
+	Events        []Event // Events are the events saved by the command
+	Version       Version // Version is the aggregate version after the command
+	GlobalVersion Version // GlobalVersion is the global version of the last saved event
+}
+
+// CommandHandlerFunc handles a command
+type CommandHandlerFunc func(ctx context.Context, cmd interface{}) (CommandResult, error)
+
+// Middleware wraps the command handling, e.g. to validate, authorize or log commands
+type Middleware func(next CommandHandlerFunc) CommandHandlerFunc
+
+// CommandBus dispatches commands to typed handlers. The target aggregate is loaded from the repository
+// before the handler is called and saved after it returns.
+type CommandBus struct {
+	repo       *EventRepository
+	handlers   map[reflect.Type]CommandHandlerFunc
+	middleware []Middleware
+}
+
+// NewCommandBus factory function
+func NewCommandBus(repo *EventRepository) *CommandBus {
+	return &CommandBus{
+		repo:     repo,
+		handlers: make(map[reflect.Type]CommandHandlerFunc),
+	}
+}
+
+// Use adds middleware to the command bus. The first added middleware is the outermost.
+func (b *CommandBus) Use(middleware ...Middleware) {
+	b.middleware = append(b.middleware, middleware...)
+}
+
+// HandleCommand register the handler for commands of type C that targets an existing aggregate of type A. The idF returns
+// the id of the aggregate to load. A previously registered handler for the same command type is replaced.
+func HandleCommand[C any, A aggregate](b *CommandBus, idF func(cmd *C) string, f func(ctx context.Context, cmd *C, a A) error) {
+	b.handlers[reflect.TypeOf((*C)(nil)).Elem()] = func(ctx context.Context, cmd interface{}) (CommandResult, error) {
+		c := command[C](cmd)
+		a := newAggregate[A]()
+		handled, err := b.get(ctx, idF(c), a)
+		if err != nil {
+			return CommandResult{}, err
+		}
+		if handled {
+			return result(a, nil), nil
+		}
+		err = f(ctx, c, a)
+		if err != nil {
+			return CommandResult{}, err
+		}
+		return b.save(ctx, a)
+	}
+}
+
+// HandleCreateCommand register the handler for commands of type C that creates a new aggregate of type A. A previously
+// registered handler for the same command type is replaced. A command dispatched with a command id is only skipped if
+// the handler creates the aggregate with the same id each time, e.g. an id derived from the command.
+func HandleCreateCommand[C any, A aggregate](b *CommandBus, f func(ctx context.Context, cmd *C) (A, error)) {
+	b.handlers[reflect.TypeOf((*C)(nil)).Elem()] = func(ctx context.Context, cmd interface{}) (CommandResult, error) {
+		a, err := f(ctx, command[C](cmd))
+		if err != nil {
+			return CommandResult{}, err
+		}
+		if _, ok := CommandID(ctx); ok {
+			existing := newAggregate[A]()
+			handled, err := b.get(ctx, a.Root().ID(), existing)
+			if err != nil && !errors.Is(err, ErrAggregateNotFound) {
+				return CommandResult{}, err
+			}
+			if handled {
+				return result(existing, nil), nil
+			}
+		}
+		return b.save(ctx, a)
+	}
+}
+
+// Dispatch calls the handler registered for the command type via the middleware. The command can be passed as a value
+// or a pointer.
+func (b *CommandBus) Dispatch(ctx context.Context, cmd interface{}) (CommandResult, error) {
+	t := reflect.TypeOf(cmd)
+	if t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	f, ok := b.handlers[t]
+	if !ok {
+		return CommandResult{}, fmt.Errorf("%w: %v", ErrCommandNotHandled, t)
+	}
+	for i := len(b.middleware) - 1; i >= 0; i-- {
+		f = b.middleware[i](f)
+	}
+	return f(ctx, cmd)
+}
+
+// DispatchSaga dispatches the command issued by a saga with the saga command id. It has the signature of the saga
+// dispatch func. A command that is dispatched again after a crash, before the saga instance was saved, is skipped by
+// the target aggregate.
+func (b *CommandBus) DispatchSaga(ctx context.Context, cmd SagaCommand) error {
+	_, err := b.Dispatch(WithCommandID(ctx, cmd.ID), cmd.Command)
+	return err
+}
+
+// get builds the aggregate and returns true if it has handled the command with the command id in the context
+func (b *CommandBus) get(ctx context.Context, id string, a aggregate) (bool, error) {
+	commandID, ok := CommandID(ctx)
+	var handled bool
+	err := b.repo.get(ctx, id, a, func(e Event) {
+		if ok && e.Metadata()[CommandIDKey] == commandID {
+			handled = true
+		}
+	})
+	return handled, err
+}
+
+// save saves the aggregate and returns the saved events. The events are marked with the command id from the context.
+func (b *CommandBus) save(ctx context.Context, a aggregate) (CommandResult, error) {
+	root := a.Root()
+	if commandID, ok := CommandID(ctx); ok {
+		for i := range root.aggregateEvents {
+			if root.aggregateEvents[i].metadata == nil {
+				root.aggregateEvents[i].metadata = make(map[string]interface{})
+			}
+			root.aggregateEvents[i].metadata[CommandIDKey] = commandID
+		}
+	}
+	// the global versions are set on the events during the save
+	events := root.aggregateEvents
+	err := b.repo.Save(a)
+	if err != nil {
+		return CommandResult{}, err
+	}
+	return result(a, events), nil
+}
+
+// result returns the command result of the aggregate and the saved events
+func result(a aggregate, events []Event) CommandResult {
+	root := a.Root()
+	return CommandResult{
+		AggregateID:   root.ID(),
+		Events:        append([]Event{}, events...),
+		Version:       root.Version(),
+		GlobalVersion: root.GlobalVersion(),
+	}
+}
+
+// newAggregate returns a new aggregate of type A
+func newAggregate[A aggregate]() A {
+	return reflect.New(reflect.TypeOf((*A)(nil)).Elem().Elem()).Interface().(A)
+}
+
+// command returns the command as a pointer
+func command[C any](cmd interface{}) *C {
+	if c, ok := cmd.(*C); ok {
+		return c
+	}
+	c := cmd.(C)
+	return &c
+}
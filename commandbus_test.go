@@ -0,0 +1,179 @@
+package eventsourcing_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/eventstore/memory"
+)
+
+type CreatePersonCommand struct {
+	Name string
+}
+
+type GrowOlderCommand struct {
+	ID string
+}
+
+func commandBus() (*eventsourcing.CommandBus, *eventsourcing.EventRepository) {
+	repo := eventsourcing.NewEventRepository(memory.Create())
+	repo.Register(&Person{})
+
+	bus := eventsourcing.NewCommandBus(repo)
+	eventsourcing.HandleCreateCommand(bus, func(ctx context.Context, cmd *CreatePersonCommand) (*Person, error) {
+		return CreatePerson(cmd.Name)
+	})
+	eventsourcing.HandleCommand(bus, func(cmd *GrowOlderCommand) string { return cmd.ID }, func(ctx context.Context, cmd *GrowOlderCommand, person *Person) error {
+		person.GrowOlder()
+		return nil
+	})
+	return bus, repo
+}
+
+func TestCommandBus(t *testing.T) {
+	bus, repo := commandBus()
+	ctx := context.Background()
+
+	result, err := bus.Dispatch(ctx, CreatePersonCommand{Name: "kalle"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Events) != 1 || result.Version != 1 || result.GlobalVersion != 1 {
+		t.Fatalf("wrong command result %v", result)
+	}
+	if result.Events[0].GlobalVersion() != 1 {
+		t.Fatalf("expected the global version on the returned event got %d", result.Events[0].GlobalVersion())
+	}
+
+	result, err = bus.Dispatch(ctx, &GrowOlderCommand{ID: result.AggregateID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Events) != 1 || result.Version != 2 || result.GlobalVersion != 2 {
+		t.Fatalf("wrong command result %v", result)
+	}
+
+	person := &Person{}
+	err = repo.Get(result.AggregateID, person)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if person.Name != "kalle" || person.Age != 1 {
+		t.Fatalf("wrong person state %v", person)
+	}
+}
+
+func TestCommandBusErrors(t *testing.T) {
+	bus, _ := commandBus()
+	ctx := context.Background()
+
+	_, err := bus.Dispatch(ctx, struct{}{})
+	if !errors.Is(err, eventsourcing.ErrCommandNotHandled) {
+		t.Fatalf("expected ErrCommandNotHandled got %v", err)
+	}
+	_, err = bus.Dispatch(ctx, GrowOlderCommand{ID: "none existing"})
+	if !errors.Is(err, eventsourcing.ErrAggregateNotFound) {
+		t.Fatalf("expected ErrAggregateNotFound got %v", err)
+	}
+}
+
+func TestCommandBusMiddleware(t *testing.T) {
+	bus, _ := commandBus()
+	ctx := context.Background()
+
+	ErrInvalid := errors.New("invalid command")
+	calls := make([]string, 0)
+	bus.Use(func(next eventsourcing.CommandHandlerFunc) eventsourcing.CommandHandlerFunc {
+		return func(ctx context.Context, cmd interface{}) (eventsourcing.CommandResult, error) {
+			calls = append(calls, "log")
+			return next(ctx, cmd)
+		}
+	}, func(next eventsourcing.CommandHandlerFunc) eventsourcing.CommandHandlerFunc {
+		return func(ctx context.Context, cmd interface{}) (eventsourcing.CommandResult, error) {
+			calls = append(calls, "validate")
+			if c, ok := cmd.(CreatePersonCommand); ok && c.Name == "" {
+				return eventsourcing.CommandResult{}, ErrInvalid
+			}
+			return next(ctx, cmd)
+		}
+	})
+
+	_, err := bus.Dispatch(ctx, CreatePersonCommand{})
+	if !errors.Is(err, ErrInvalid) {
+		t.Fatalf("expected invalid command error got %v", err)
+	}
+	if len(calls) != 2 || calls[0] != "log" || calls[1] != "validate" {
+		t.Fatalf("expected the middleware to be called in order got %v", calls)
+	}
+}
+
+func TestCommandBusCommandID(t *testing.T) {
+	bus, repo := commandBus()
+	ctx := context.Background()
+
+	result, err := bus.Dispatch(ctx, CreatePersonCommand{Name: "kalle"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := result.AggregateID
+
+	// the second dispatch with the same command id is skipped
+	for i := 0; i < 2; i++ {
+		result, err = bus.Dispatch(eventsourcing.WithCommandID(ctx, "grow-1"), GrowOlderCommand{ID: id})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(result.Events) != 0 || result.Version != 2 {
+		t.Fatalf("expected the handled command to be skipped got %v", result)
+	}
+	_, err = bus.Dispatch(eventsourcing.WithCommandID(ctx, "grow-2"), GrowOlderCommand{ID: id})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	person := &Person{}
+	err = repo.Get(id, person)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if person.Age != 2 {
+		t.Fatalf("expected age 2 got %d", person.Age)
+	}
+}
+
+func TestCommandBusDispatchSagaRedelivered(t *testing.T) {
+	bus, repo := commandBus()
+	ctx := context.Background()
+
+	result, err := bus.Dispatch(ctx, CreatePersonCommand{Name: "kalle"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	born := result.Events[0]
+
+	// the saga handler tracks no state change which makes the saga instance unsaved, as if the process crashed
+	// after the command was dispatched
+	saga := eventsourcing.NewSaga(repo, "birthday", func() *Birthday { return &Birthday{} }, func(b *Birthday, e eventsourcing.Event) ([]interface{}, error) {
+		return []interface{}{GrowOlderCommand{ID: e.AggregateID()}}, nil
+	}, bus.DispatchSaga)
+
+	// the event is delivered twice to the saga
+	for i := 0; i < 2; i++ {
+		err = saga.Handle(born)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	person := &Person{}
+	err = repo.Get(result.AggregateID, person)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if person.Age != 1 {
+		t.Fatalf("expected the command to be handled once got age %d", person.Age)
+	}
+}
@@ -0,0 +1,68 @@
+package eventsourcing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrProjectionNotInGroup is returned when waiting on a projection name that is not in the group
+var ErrProjectionNotInGroup = errors.New("projection not in group")
+
+// SaveWithToken saves the aggregate events and returns the global version of the last saved event. The global
+// version can be used as a token to wait for projections to handle the saved events via WaitUntil.
+func (er *EventRepository) SaveWithToken(a aggregate) (Version, error) {
+	err := er.Save(a)
+	if err != nil {
+		return 0, err
+	}
+	return a.Root().GlobalVersion(), nil
+}
+
+// WaitUntil blocks until the projection has handled the event with the global version or the context is done.
+// The projection is triggered if it's running and has not reached the global version.
+func (p *Projection) WaitUntil(ctx context.Context, globalVersion Version) error {
+	if p.Position() >= globalVersion {
+		return nil
+	}
+	p.TriggerAsync()
+	for p.Position() < globalVersion {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Millisecond * 10):
+		}
+	}
+	return nil
+}
+
+// WaitUntil blocks until the named projections in the group has handled the event with the global version
+// or the context is done. The names are matched on the projection Key, that includes the version when set, which
+// separates a live projection from its rebuild. If no names are given it waits for all projections in the group.
+func (g *Group) WaitUntil(ctx context.Context, globalVersion Version, names ...string) error {
+	projections := g.members()
+	if len(names) > 0 {
+		named := make([]*Projection, 0, len(names))
+		for _, name := range names {
+			found := false
+			for _, p := range projections {
+				if p.Key() == name {
+					named = append(named, p)
+					found = true
+				}
+			}
+			if !found {
+				return fmt.Errorf("%w: %s", ErrProjectionNotInGroup, name)
+			}
+		}
+		projections = named
+	}
+	for _, p := range projections {
+		err := p.WaitUntil(ctx, globalVersion)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package eventsourcing_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/eventstore/memory"
+)
+
+func TestWaitUntil(t *testing.T) {
+	es := memory.Create()
+	repo := eventsourcing.NewEventRepository(es)
+	repo.Register(&Person{})
+
+	names := &readModel{}
+	p := repo.Projections.ProjectionFrom(es.AllFrom(1), func(event eventsourcing.Event) error {
+		switch e := event.Data().(type) {
+		case *Born:
+			names.add(e.Name)
+		}
+		return nil
+	})
+	p.Name = "names"
+	g := repo.Projections.Group(p)
+	// the pace is to slow for the projection to run in the test
+	g.Pace = time.Hour
+	g.Start()
+	defer g.Stop()
+	g.TriggerSync()
+
+	person, err := CreatePerson("kalle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := repo.SaveWithToken(person)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != 1 {
+		t.Fatalf("expected token 1 got %d", token)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	err = g.WaitUntil(ctx, token, "names")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names.count() != 1 {
+		t.Fatalf("expected the saved person in the read-model got %d names", names.count())
+	}
+
+	err = g.WaitUntil(ctx, token, "unknown")
+	if !errors.Is(err, eventsourcing.ErrProjectionNotInGroup) {
+		t.Fatalf("expected ErrProjectionNotInGroup got %v", err)
+	}
+}
+
+func TestWaitUntilTimeout(t *testing.T) {
+	es := memory.Create()
+	p := eventsourcing.NewProjectionHandler(eventsourcing.NewRegister(), eventsourcing.EncoderJSON{})
+	proj := p.ProjectionFrom(es.AllFrom(1), func(event eventsourcing.Event) error {
+		return nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*20)
+	defer cancel()
+	err := proj.WaitUntil(ctx, 1)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded got %v", err)
+	}
+}
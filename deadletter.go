@@ -0,0 +1,125 @@
+package eventsourcing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hallgren/eventsourcing/core"
+)
+
+// ErrNoDeadLetterStore is returned when handling parked events on a projection without a dead-letter store
+var ErrNoDeadLetterStore = errors.New("projection has no dead-letter store")
+
+// RetryPolicy sets how a failing projection callback is retried. The wait time between the retries starts at
+// Backoff and is doubled after each retry until it reaches MaxBackoff.
+type RetryPolicy struct {
+	MaxRetries int           // MaxRetries is the number of retries after the first failing call. Default 0 (no retry)
+	Backoff    time.Duration // Backoff is the wait time before the first retry
+	MaxBackoff time.Duration // MaxBackoff is the upper limit of the wait time, no limit if zero
+}
+
+// wait returns the wait time before the retry
+func (r RetryPolicy) wait(retry int) time.Duration {
+	wait := r.Backoff
+	for i := 0; i < retry; i++ {
+		wait *= 2
+		if r.MaxBackoff > 0 && wait > r.MaxBackoff {
+			return r.MaxBackoff
+		}
+	}
+	return wait
+}
+
+// retry calls f until it succeeds or the retries in the retry policy are exhausted. The wait between the retries
+// is stopped if the context is done.
+func (p *Projection) retry(ctx context.Context, f func() error) error {
+	err := f()
+	// an event without handler will not be handled in a retry
+	for retry := 0; err != nil && !errors.Is(err, ErrEventNotHandled) && retry < p.Retry.MaxRetries; retry++ {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-p.clock().After(p.Retry.wait(retry)):
+		}
+		err = f()
+	}
+	return err
+}
+
+// handle calls the callback and retries it according to the retry policy. If the callback still
+// fails the event is parked in the dead-letter store, if the projection has one.
+func (p *Projection) handle(ctx context.Context, e Event) error {
+	err := p.retry(ctx, func() error {
+		return p.callbackF(e)
+	})
+	if errors.Is(err, ErrEventNotHandled) {
+		// an event without handler is treated as an event not found in the register
+		if p.Strict {
+			return fmt.Errorf("event not handled aggregate type: %s, reason: %s, global version: %d, %w", e.AggregateType(), e.Reason(), e.GlobalVersion(), err)
+		}
+		return nil
+	}
+	// an event interrupted by the context is not parked as it's handled again in the next run
+	if err == nil || p.DeadLetters == nil || ctx.Err() != nil {
+		return err
+	}
+	return p.DeadLetters.Park(ctx, DeadLetter{
+		Projection: p.Key(),
+		Event:      e.event,
+		Error:      err.Error(),
+		Timestamp:  p.clock().Now().UTC(),
+	})
+}
+
+// ParkedEvents returns the events parked in the dead-letter store by the projection
+func (p *Projection) ParkedEvents(ctx context.Context) ([]DeadLetter, error) {
+	if p.DeadLetters == nil {
+		return nil, ErrNoDeadLetterStore
+	}
+	return p.DeadLetters.List(ctx, p.Key())
+}
+
+// RetryParked calls the projection callback with the parked event and removes it from the dead-letter store
+// if the callback succeeds. It's up to the caller to make sure the callback is not called concurrently from
+// a running projection.
+func (p *Projection) RetryParked(ctx context.Context, globalVersion Version) error {
+	deadLetter, err := p.parked(ctx, globalVersion)
+	if err != nil {
+		return err
+	}
+	e, found, err := p.decode(deadLetter.Event)
+	if err != nil {
+		return err
+	}
+	if found {
+		err = p.callbackF(e)
+		if err != nil {
+			return err
+		}
+	}
+	return p.DeadLetters.Remove(ctx, p.Key(), core.Version(globalVersion))
+}
+
+// DiscardParked removes the parked event from the dead-letter store without handling it
+func (p *Projection) DiscardParked(ctx context.Context, globalVersion Version) error {
+	if p.DeadLetters == nil {
+		return ErrNoDeadLetterStore
+	}
+	return p.DeadLetters.Remove(ctx, p.Key(), core.Version(globalVersion))
+}
+
+// parked returns the parked event with the global version
+func (p *Projection) parked(ctx context.Context, globalVersion Version) (DeadLetter, error) {
+	deadLetters, err := p.ParkedEvents(ctx)
+	if err != nil {
+		return DeadLetter{}, err
+	}
+	for _, d := range deadLetters {
+		if d.Event.GlobalVersion == core.Version(globalVersion) {
+			return d, nil
+		}
+	}
+	return DeadLetter{}, ErrDeadLetterNotFound
+}
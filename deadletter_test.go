@@ -0,0 +1,244 @@
+package eventsourcing_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hallgren/eventsourcing"
+	deadletter "github.com/hallgren/eventsourcing/deadletterstore/memory"
+	"github.com/hallgren/eventsourcing/eventsourcingtest"
+	"github.com/hallgren/eventsourcing/eventstore/memory"
+)
+
+func TestRetry(t *testing.T) {
+	// setup
+	es := memory.Create()
+	register := eventsourcing.NewRegister()
+	register.Register(&Person{})
+
+	err := createPersonEvent(es, "kalle", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	calls := 0
+	p := eventsourcing.NewProjectionHandler(register, eventsourcing.EncoderJSON{})
+	proj := p.Projection(es.All(0, 1), func(event eventsourcing.Event) error {
+		calls++
+		if calls < 3 {
+			return errors.New("temporary error")
+		}
+		return nil
+	})
+	proj.Retry = eventsourcing.RetryPolicy{MaxRetries: 2, Backoff: time.Millisecond}
+
+	_, result := proj.RunOnce()
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	if calls != 3 {
+		t.Fatalf("expected 3 calls to the callback got %d", calls)
+	}
+}
+
+func TestRetryExhausted(t *testing.T) {
+	// setup
+	es := memory.Create()
+	register := eventsourcing.NewRegister()
+	register.Register(&Person{})
+
+	err := createPersonEvent(es, "kalle", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ErrApplication := errors.New("application error")
+	calls := 0
+	p := eventsourcing.NewProjectionHandler(register, eventsourcing.EncoderJSON{})
+	proj := p.Projection(es.All(0, 1), func(event eventsourcing.Event) error {
+		calls++
+		return ErrApplication
+	})
+	proj.Retry = eventsourcing.RetryPolicy{MaxRetries: 3, Backoff: time.Millisecond, MaxBackoff: time.Millisecond * 2}
+
+	_, result := proj.RunOnce()
+	if !errors.Is(result.Error, ErrApplication) {
+		t.Fatalf("expected application error got %v", result.Error)
+	}
+	if calls != 4 {
+		t.Fatalf("expected 4 calls to the callback got %d", calls)
+	}
+}
+
+func TestRetryContextCancel(t *testing.T) {
+	// setup
+	es := memory.Create()
+	register := eventsourcing.NewRegister()
+	register.Register(&Person{})
+
+	err := createPersonEvent(es, "kalle", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the fake clock is never advanced and the projection waits for the backoff until the context is canceled
+	clock := eventsourcingtest.NewFakeClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	p := eventsourcing.NewProjectionHandler(register, eventsourcing.EncoderJSON{})
+	proj := p.Projection(es.All(0, 1), func(event eventsourcing.Event) error {
+		return errors.New("temporary error")
+	})
+	proj.Clock = clock
+	proj.Retry = eventsourcing.RetryPolicy{MaxRetries: 3, Backoff: time.Hour}
+	proj.DeadLetters = deadletter.Create()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error)
+	go func() {
+		done <- proj.Run(ctx, time.Second)
+	}()
+	for clock.Waiters() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+
+	select {
+	case err = <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected the projection to stop while waiting for the retry")
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context canceled got %v", err)
+	}
+	// the interrupted event is handled again in the next run and not parked
+	parked, err := proj.ParkedEvents(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parked) != 0 {
+		t.Fatalf("expected no parked events got %d", len(parked))
+	}
+	if proj.Position() != 0 {
+		t.Fatalf("expected position 0 got %d", proj.Position())
+	}
+}
+
+func TestDeadLetter(t *testing.T) {
+	// setup
+	es := memory.Create()
+	register := eventsourcing.NewRegister()
+	register.Register(&Person{})
+
+	err := createPersonEvent(es, "kalle", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fail := true
+	handled := make([]eventsourcing.Version, 0)
+	p := eventsourcing.NewProjectionHandler(register, eventsourcing.EncoderJSON{})
+	proj := p.Projection(es.All(0, 1), func(event eventsourcing.Event) error {
+		if fail && event.GlobalVersion() == 2 {
+			return errors.New("could not handle event")
+		}
+		handled = append(handled, event.GlobalVersion())
+		return nil
+	})
+	proj.Name = "person"
+	proj.DeadLetters = deadletter.Create()
+
+	// the failing event is parked and the projection continues
+	result := proj.RunToEnd(context.Background())
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	if len(handled) != 2 || handled[0] != 1 || handled[1] != 3 {
+		t.Fatalf("expected event 1 and 3 to be handled got %v", handled)
+	}
+
+	parked, err := proj.ParkedEvents(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parked) != 1 {
+		t.Fatalf("expected one parked event got %d", len(parked))
+	}
+	if parked[0].Event.GlobalVersion != 2 || parked[0].Error != "could not handle event" || parked[0].Projection != "person" {
+		t.Fatalf("wrong parked event %v", parked[0])
+	}
+
+	// retry the parked event with a callback that fails
+	err = proj.RetryParked(context.Background(), 2)
+	if err == nil {
+		t.Fatal("expected error when retrying parked event")
+	}
+
+	fail = false
+	err = proj.RetryParked(context.Background(), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if handled[2] != 2 {
+		t.Fatalf("expected the parked event to be handled got %v", handled)
+	}
+	parked, err = proj.ParkedEvents(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parked) != 0 {
+		t.Fatalf("expected no parked events got %d", len(parked))
+	}
+
+	err = proj.RetryParked(context.Background(), 2)
+	if !errors.Is(err, eventsourcing.ErrDeadLetterNotFound) {
+		t.Fatalf("expected ErrDeadLetterNotFound got %v", err)
+	}
+}
+
+func TestDiscardParked(t *testing.T) {
+	// setup
+	es := memory.Create()
+	register := eventsourcing.NewRegister()
+	register.Register(&Person{})
+
+	err := createPersonEvent(es, "kalle", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := eventsourcing.NewProjectionHandler(register, eventsourcing.EncoderJSON{})
+	proj := p.Projection(es.All(0, 1), func(event eventsourcing.Event) error {
+		return errors.New("could not handle event")
+	})
+	proj.DeadLetters = deadletter.Create()
+
+	_, result := proj.RunOnce()
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+
+	err = proj.DiscardParked(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parked, err := proj.ParkedEvents(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parked) != 0 {
+		t.Fatalf("expected no parked events got %d", len(parked))
+	}
+}
+
+func TestNoDeadLetterStore(t *testing.T) {
+	p := eventsourcing.NewProjectionHandler(eventsourcing.NewRegister(), eventsourcing.EncoderJSON{})
+	proj := p.Projection(memory.Create().All(0, 1), func(event eventsourcing.Event) error {
+		return nil
+	})
+	_, err := proj.ParkedEvents(context.Background())
+	if !errors.Is(err, eventsourcing.ErrNoDeadLetterStore) {
+		t.Fatalf("expected ErrNoDeadLetterStore got %v", err)
+	}
+}
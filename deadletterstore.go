@@ -0,0 +1,27 @@
+package eventsourcing
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/hallgren/eventsourcing/core"
+)
+
+// ErrDeadLetterNotFound returned when the event is not parked in the dead-letter store
+var ErrDeadLetterNotFound = errors.New("dead letter not found")
+
+// DeadLetter holds an event that a projection failed to handle
+type DeadLetter struct {
+	Projection string     // name of the projection that failed to handle the event
+	Event      core.Event // the parked event
+	Error      string     // the error returned from the last attempt
+	Timestamp  time.Time  // when the event was parked
+}
+
+// DeadLetterStore expose the methods a dead-letter store must uphold
+type DeadLetterStore interface {
+	Park(ctx context.Context, deadLetter DeadLetter) error
+	List(ctx context.Context, projection string) ([]DeadLetter, error)
+	Remove(ctx context.Context, projection string, globalVersion core.Version) error
+}
@@ -0,0 +1,67 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/core"
+)
+
+type Memory struct {
+	deadLetters map[string]map[core.Version]eventsourcing.DeadLetter
+	lock        sync.Mutex
+}
+
+// Create in memory dead-letter store
+func Create() *Memory {
+	return &Memory{
+		deadLetters: make(map[string]map[core.Version]eventsourcing.DeadLetter),
+	}
+}
+
+func (m *Memory) Close() {
+
+}
+
+// Park stores the dead letter
+func (m *Memory) Park(ctx context.Context, deadLetter eventsourcing.DeadLetter) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	parked, ok := m.deadLetters[deadLetter.Projection]
+	if !ok {
+		parked = make(map[core.Version]eventsourcing.DeadLetter)
+		m.deadLetters[deadLetter.Projection] = parked
+	}
+	parked[deadLetter.Event.GlobalVersion] = deadLetter
+	return nil
+}
+
+// List returns the projection dead letters in global version order
+func (m *Memory) List(ctx context.Context, projection string) ([]eventsourcing.DeadLetter, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	deadLetters := make([]eventsourcing.DeadLetter, 0, len(m.deadLetters[projection]))
+	for _, d := range m.deadLetters[projection] {
+		deadLetters = append(deadLetters, d)
+	}
+	sort.Slice(deadLetters, func(i, j int) bool {
+		return deadLetters[i].Event.GlobalVersion < deadLetters[j].Event.GlobalVersion
+	})
+	return deadLetters, nil
+}
+
+// Remove deletes the dead letter
+func (m *Memory) Remove(ctx context.Context, projection string, globalVersion core.Version) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if _, ok := m.deadLetters[projection][globalVersion]; !ok {
+		return eventsourcing.ErrDeadLetterNotFound
+	}
+	delete(m.deadLetters[projection], globalVersion)
+	return nil
+}
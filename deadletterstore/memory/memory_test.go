@@ -0,0 +1,17 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/deadletterstore/memory"
+	"github.com/hallgren/eventsourcing/eventsourcingtest"
+)
+
+func TestSuite(t *testing.T) {
+	f := func() (eventsourcing.DeadLetterStore, func(), error) {
+		ds := memory.Create()
+		return ds, func() { ds.Close() }, nil
+	}
+	eventsourcingtest.TestDeadLetterStore(t, f)
+}
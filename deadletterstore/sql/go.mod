@@ -0,0 +1,11 @@
+module github.com/hallgren/eventsourcing/deadletterstore/sql
+
+go 1.13
+
+require (
+	github.com/hallgren/eventsourcing v0.6.0
+	github.com/hallgren/eventsourcing/core v0.4.0
+	github.com/mattn/go-sqlite3 v1.14.22
+)
+
+replace github.com/hallgren/eventsourcing => ../..
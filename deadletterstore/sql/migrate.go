@@ -0,0 +1,37 @@
+package sql
+
+import "context"
+
+const createTable = `create table dead_letters (projection VARCHAR(255) NOT NULL, seq INTEGER NOT NULL, id VARCHAR(255) NOT NULL, version INTEGER, reason VARCHAR(255), type VARCHAR(255), timestamp VARCHAR(255), data BLOB, metadata BLOB, error TEXT, parked_at VARCHAR(255));`
+
+// Migrate the database
+func (s *SQL) Migrate() error {
+	sqlStmt := []string{
+		createTable,
+		`create unique index projection_seq on dead_letters (projection, seq);`,
+	}
+	return s.migrate(sqlStmt)
+}
+
+func (s *SQL) migrate(stm []string) error {
+	tx, err := s.db.BeginTx(context.Background(), nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// check if the migration is already done
+	rows, err := tx.Query(`Select count(*) from dead_letters`)
+	if err == nil {
+		rows.Close()
+		return nil
+	}
+
+	for _, b := range stm {
+		_, err := tx.Exec(b)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
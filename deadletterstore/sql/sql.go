@@ -0,0 +1,100 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/core"
+)
+
+type SQL struct {
+	db *sql.DB
+}
+
+// Open connection to database
+func Open(db *sql.DB) *SQL {
+	return &SQL{
+		db: db,
+	}
+}
+
+// Close the connection
+func (s *SQL) Close() {
+	s.db.Close()
+}
+
+// Park persists the dead letter, an already parked event is replaced
+func (s *SQL) Park(ctx context.Context, deadLetter eventsourcing.DeadLetter) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.New(fmt.Sprintf("could not start a write transaction, %v", err))
+	}
+	defer tx.Rollback()
+
+	event := deadLetter.Event
+	_, err = tx.ExecContext(ctx, `DELETE FROM dead_letters where projection=$1 AND seq=$2`, deadLetter.Projection, event.GlobalVersion)
+	if err != nil {
+		return err
+	}
+	statement := `INSERT INTO dead_letters (projection, seq, id, version, reason, type, timestamp, data, metadata, error, parked_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
+	_, err = tx.ExecContext(ctx, statement, deadLetter.Projection, event.GlobalVersion, event.AggregateID, event.Version, event.Reason, event.AggregateType, event.Timestamp.Format(time.RFC3339), event.Data, event.Metadata, deadLetter.Error, deadLetter.Timestamp.Format(time.RFC3339))
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// List returns the projection dead letters in global version order
+func (s *SQL) List(ctx context.Context, projection string) ([]eventsourcing.DeadLetter, error) {
+	selectStm := `Select seq, id, version, reason, type, timestamp, data, metadata, error, parked_at from dead_letters where projection=$1 order by seq asc`
+	rows, err := s.db.QueryContext(ctx, selectStm, projection)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deadLetters := make([]eventsourcing.DeadLetter, 0)
+	for rows.Next() {
+		var event core.Event
+		var timestamp, reason, parkedAt string
+		err = rows.Scan(&event.GlobalVersion, &event.AggregateID, &event.Version, &event.Reason, &event.AggregateType, &timestamp, &event.Data, &event.Metadata, &reason, &parkedAt)
+		if err != nil {
+			return nil, err
+		}
+		event.Timestamp, err = time.Parse(time.RFC3339, timestamp)
+		if err != nil {
+			return nil, err
+		}
+		t, err := time.Parse(time.RFC3339, parkedAt)
+		if err != nil {
+			return nil, err
+		}
+		deadLetters = append(deadLetters, eventsourcing.DeadLetter{
+			Projection: projection,
+			Event:      event,
+			Error:      reason,
+			Timestamp:  t,
+		})
+	}
+	return deadLetters, rows.Err()
+}
+
+// Remove deletes the dead letter
+func (s *SQL) Remove(ctx context.Context, projection string, globalVersion core.Version) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM dead_letters where projection=$1 AND seq=$2`, projection, globalVersion)
+	if err != nil {
+		return err
+	}
+	removed, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if removed == 0 {
+		return eventsourcing.ErrDeadLetterNotFound
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package sql_test
+
+import (
+	sqldriver "database/sql"
+	"testing"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/deadletterstore/sql"
+	"github.com/hallgren/eventsourcing/eventsourcingtest"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestSuite(t *testing.T) {
+	f := func() (eventsourcing.DeadLetterStore, func(), error) {
+		return deadletterstore()
+	}
+	eventsourcingtest.TestDeadLetterStore(t, f)
+}
+
+func TestMultipleMigrate(t *testing.T) {
+	ds, close, err := deadletterstore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer close()
+	err = ds.Migrate()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func deadletterstore() (*sql.SQL, func(), error) {
+	db, err := sqldriver.Open("sqlite3", "file::memory:?cache=shared")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	db.SetMaxOpenConns(1)
+	err = db.Ping()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	store := sql.Open(db)
+	err = store.Migrate()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return store, func() {
+		store.Close()
+	}, nil
+}
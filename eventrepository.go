@@ -24,6 +24,11 @@ type EventSubscribers interface {
 	Name(f func(e Event), aggregate string, events ...string) *subscription
 }
 
+// Trigger is a projection or a group of projections that can be triggered to run
+type Trigger interface {
+	TriggerAsync()
+}
+
 type encoder interface {
 	Serialize(v interface{}) ([]byte, error)
 	Deserialize(data []byte, v interface{}) error
@@ -52,6 +57,8 @@ type EventRepository struct {
 	// encoder to serialize / deserialize events
 	encoder     encoder
 	Projections *ProjectionHandler
+	// triggers are triggered after events are saved
+	triggers []Trigger
 }
 
 // NewRepository factory function
@@ -80,6 +87,14 @@ func (er *EventRepository) Register(a aggregate) {
 	er.register.Register(a)
 }
 
+// TriggerOnSave triggers the projections or groups when events are saved via the repository. It makes the
+// projections handle local saves directly instead of waiting for the pace. Events saved from other processes
+// are still handled by the pace.
+// Should be called before the repository is used to save events.
+func (er *EventRepository) TriggerOnSave(triggers ...Trigger) {
+	er.triggers = append(er.triggers, triggers...)
+}
+
 // Subscribers returns an interface with all event subscribers
 func (er *EventRepository) Subscribers() EventSubscribers {
 	return er.eventStream
@@ -87,26 +102,38 @@ func (er *EventRepository) Subscribers() EventSubscribers {
 
 // Save an aggregates events
 func (er *EventRepository) Save(a aggregate) error {
+	publish, err := er.SaveWith(a, er.eventStore.Save)
+	if err != nil {
+		return err
+	}
+	publish()
+	return nil
+}
+
+// SaveWith saves the aggregate events via the saveF, e.g. to save them in a database transaction controlled by the
+// caller. The returned publish func publishes the events to the subscribers, triggers the projections and updates the
+// aggregate. Call it when the transaction is committed.
+func (er *EventRepository) SaveWith(a aggregate, saveF func(events []core.Event) error) (publish func(), err error) {
 	var esEvents = make([]core.Event, 0)
 
 	if !er.register.AggregateRegistered(a) {
-		return ErrAggregateNotRegistered
+		return nil, ErrAggregateNotRegistered
 	}
 	root := a.Root()
 
 	// return as quick as possible when no events to process
 	if len(root.aggregateEvents) == 0 {
-		return nil
+		return func() {}, nil
 	}
 
 	for _, event := range root.aggregateEvents {
 		data, err := er.encoder.Serialize(event.Data())
 		if err != nil {
-			return err
+			return nil, err
 		}
 		metadata, err := er.encoder.Serialize(event.Metadata())
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		esEvent := core.Event{
@@ -120,17 +147,17 @@ func (er *EventRepository) Save(a aggregate) error {
 		}
 		_, ok := er.register.EventRegistered(esEvent)
 		if !ok {
-			return ErrEventNotRegistered
+			return nil, ErrEventNotRegistered
 		}
 		esEvents = append(esEvents, esEvent)
 	}
 
-	err := er.eventStore.Save(esEvents)
+	err = saveF(esEvents)
 	if err != nil {
 		if errors.Is(err, core.ErrConcurrency) {
-			return ErrConcurrency
+			return nil, ErrConcurrency
 		}
-		return fmt.Errorf("error from event store: %w", err)
+		return nil, fmt.Errorf("error from event store: %w", err)
 	}
 
 	// update the global version on event bound to the aggregate
@@ -138,17 +165,28 @@ func (er *EventRepository) Save(a aggregate) error {
 		root.aggregateEvents[i].event.GlobalVersion = event.GlobalVersion
 	}
 
-	// publish the saved events to subscribers
-	er.eventStream.Publish(*root, root.Events())
+	return func() {
+		// publish the saved events to subscribers
+		er.eventStream.Publish(*root, root.Events())
 
-	// update the internal aggregate state
-	root.update()
-	return nil
+		// trigger the projections to handle the saved events
+		for _, t := range er.triggers {
+			t.TriggerAsync()
+		}
+
+		// update the internal aggregate state
+		root.update()
+	}, nil
 }
 
 // GetWithContext fetches the aggregates event and build up the aggregate based on it's current version.
 // The event fetching can be canceled from the outside.
 func (er *EventRepository) GetWithContext(ctx context.Context, id string, a aggregate) error {
+	return er.get(ctx, id, a, nil)
+}
+
+// get builds up the aggregate and calls eventF, if not nil, with each event applied to the aggregate
+func (er *EventRepository) get(ctx context.Context, id string, a aggregate, eventF func(e Event)) error {
 	if reflect.ValueOf(a).Kind() != reflect.Ptr {
 		return ErrAggregateNeedsToBeAPointer
 	}
@@ -189,6 +227,9 @@ func (er *EventRepository) GetWithContext(ctx context.Context, id string, a aggr
 
 			e := NewEvent(event, data, metadata)
 			root.BuildFromHistory(a, []Event{e})
+			if eventF != nil {
+				eventF(e)
+			}
 		}
 	}
 	if a.Root().Version() == 0 {
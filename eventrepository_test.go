@@ -4,9 +4,12 @@ import (
 	"context"
 	"errors"
 	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/core"
 	"github.com/hallgren/eventsourcing/eventstore/memory"
 )
 
@@ -446,3 +449,89 @@ func TestConcurrentRead(t *testing.T) {
 		}
 	}
 }
+
+func TestTriggerOnSave(t *testing.T) {
+	es := memory.Create()
+	repo := eventsourcing.NewEventRepository(es)
+	repo.Register(&Person{})
+
+	var handled atomic.Int32
+	p := repo.Projections.ProjectionFrom(es.AllFrom(1), func(event eventsourcing.Event) error {
+		handled.Add(1)
+		return nil
+	})
+	g := repo.Projections.Group(p)
+	// the pace is to slow for the projection to run in the test
+	g.Pace = time.Hour
+	g.Start()
+	defer g.Stop()
+	// wait for the first run to end
+	g.TriggerSync()
+	repo.TriggerOnSave(g)
+
+	person, err := CreatePerson("kalle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = repo.Save(person)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	timeout := time.After(time.Second)
+	for handled.Load() != 1 {
+		select {
+		case <-timeout:
+			t.Fatal("expected the saved event to be handled by the projection")
+		case <-time.After(time.Millisecond):
+		}
+	}
+}
+
+func TestSaveWith(t *testing.T) {
+	es := memory.Create()
+	repo := eventsourcing.NewEventRepository(es)
+	repo.Register(&Person{})
+
+	counter := 0
+	s := repo.Subscribers().All(func(e eventsourcing.Event) {
+		counter++
+	})
+	defer s.Close()
+
+	person, err := CreatePerson("kalle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	person.GrowOlder()
+
+	// the events are not published when the save fails, e.g. when the transaction is rolled back
+	saveErr := errors.New("rollback")
+	_, err = repo.SaveWith(person, func(events []core.Event) error {
+		return saveErr
+	})
+	if !errors.Is(err, saveErr) {
+		t.Fatalf("expected the save error got %v", err)
+	}
+	if !person.UnsavedEvents() {
+		t.Fatal("expected the events to be unsaved")
+	}
+
+	publish, err := repo.SaveWith(person, es.Save)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if counter != 0 {
+		t.Fatalf("expected no published events before publish got %d", counter)
+	}
+	publish()
+	if counter != 2 {
+		t.Fatalf("expected 2 published events got %d", counter)
+	}
+	if person.UnsavedEvents() {
+		t.Fatal("expected no unsaved events after publish")
+	}
+	if person.GlobalVersion() != 2 {
+		t.Fatalf("expected global version 2 got %d", person.GlobalVersion())
+	}
+}
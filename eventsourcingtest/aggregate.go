@@ -0,0 +1,146 @@
+// Package eventsourcingtest contains Given/When/Then fixtures to test aggregates and projections.
+package eventsourcingtest
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/core"
+)
+
+// Aggregate is the interface an aggregate implements by embedding the eventsourcing.AggregateRoot
+type Aggregate interface {
+	Root() *eventsourcing.AggregateRoot
+	Transition(event eventsourcing.Event)
+	Register(f eventsourcing.RegisterFunc)
+}
+
+// AggregateFixture tests an aggregate by building it from past events, running a command on it and asserting
+// the new events.
+type AggregateFixture[A Aggregate] struct {
+	t         testing.TB
+	aggregate A
+	err       error
+}
+
+// NewAggregateFixture creates a fixture for the aggregate
+func NewAggregateFixture[A Aggregate](t testing.TB, a A) *AggregateFixture[A] {
+	return &AggregateFixture[A]{t: t, aggregate: a}
+}
+
+// Given builds the aggregate from the event data via BuildFromHistory
+func (f *AggregateFixture[A]) Given(data ...interface{}) *AggregateFixture[A] {
+	root := f.aggregate.Root()
+	id := root.ID()
+	if id == "" {
+		id = "id"
+	}
+	events := make([]eventsourcing.Event, 0, len(data))
+	for i, d := range data {
+		version := core.Version(root.Version()) + core.Version(i) + 1
+		events = append(events, eventsourcing.NewEvent(core.Event{
+			AggregateID:   id,
+			Version:       version,
+			GlobalVersion: version,
+			AggregateType: typeName(f.aggregate),
+			Reason:        typeName(d),
+			Timestamp:     time.Now().UTC(),
+		}, d, nil))
+	}
+	root.BuildFromHistory(f.aggregate, events)
+	return f
+}
+
+// When runs the command on the aggregate
+func (f *AggregateFixture[A]) When(command func(a A) error) *AggregateFixture[A] {
+	f.err = command(f.aggregate)
+	return f
+}
+
+// WhenCreate runs the command that creates the aggregate, e.g. the aggregate constructor
+func (f *AggregateFixture[A]) WhenCreate(command func() (A, error)) *AggregateFixture[A] {
+	a, err := command()
+	f.err = err
+	if err == nil {
+		f.aggregate = a
+	}
+	return f
+}
+
+// Then asserts that the command did not return an error and that the new events on the aggregate has the expected data
+func (f *AggregateFixture[A]) Then(expected ...interface{}) {
+	f.t.Helper()
+	if f.err != nil {
+		f.t.Fatalf("expected no error got %v", f.err)
+		return
+	}
+	events := f.aggregate.Root().Events()
+	got := make([]interface{}, len(events))
+	for i, e := range events {
+		got[i] = e.Data()
+	}
+	assertData(f.t, expected, got)
+}
+
+// ThenError asserts that the command returned the expected error
+func (f *AggregateFixture[A]) ThenError(expected error) {
+	f.t.Helper()
+	if !errors.Is(f.err, expected) {
+		f.t.Fatalf("expected error %v got %v", expected, f.err)
+	}
+}
+
+// Aggregate returns the aggregate, e.g. to assert its state
+func (f *AggregateFixture[A]) Aggregate() A {
+	return f.aggregate
+}
+
+// assertData compares the event data and reports the events as a readable list if they differ
+func assertData(t testing.TB, expected, got []interface{}) {
+	t.Helper()
+	equal := len(expected) == len(got)
+	for i := 0; equal && i < len(expected); i++ {
+		equal = reflect.DeepEqual(value(expected[i]), value(got[i]))
+	}
+	if !equal {
+		t.Fatalf("events differ\nexpected:\n%s\ngot:\n%s", format(expected), format(got))
+	}
+}
+
+// value returns the value the data points to, making pointer and value data comparable
+func value(data interface{}) interface{} {
+	v := reflect.ValueOf(data)
+	if v.Kind() == reflect.Ptr && !v.IsNil() {
+		return v.Elem().Interface()
+	}
+	return data
+}
+
+// format lists the data with type names
+func format(data []interface{}) string {
+	if len(data) == 0 {
+		return "  (no events)"
+	}
+	lines := make([]string, len(data))
+	for i, d := range data {
+		lines[i] = fmt.Sprintf("  %d: %s %+v", i+1, typeName(d), value(d))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// typeName returns the name of the type that v points to
+func typeName(v interface{}) string {
+	t := reflect.TypeOf(v)
+	if t == nil {
+		return "nil"
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Name()
+}
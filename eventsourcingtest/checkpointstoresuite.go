@@ -0,0 +1,132 @@
+package eventsourcingtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hallgren/eventsourcing"
+)
+
+type checkpointstoreFunc = func() (eventsourcing.CheckpointStore, func(), error)
+
+func TestCheckpointStore(t *testing.T, csFunc checkpointstoreFunc) {
+	tests := []struct {
+		title string
+		run   func(cs eventsourcing.CheckpointStore) error
+	}{
+		{"should save and get checkpoint", saveAndGetCheckpoint},
+		{"should overwrite checkpoint", overwriteCheckpoint},
+		{"should get error when getting none existing checkpoint", getNoneExistingCheckpoint},
+		{"should delete checkpoint", deleteCheckpoint},
+		{"should get error when deleting none existing checkpoint", deleteNoneExistingCheckpoint},
+	}
+
+	for _, test := range tests {
+		t.Run(test.title, func(t *testing.T) {
+			cs, closeFunc, err := csFunc()
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = test.run(cs)
+			if err != nil {
+				// make use of t.Error instead of t.Fatal to make sure the closeFunc is executed
+				t.Error(err)
+			}
+			closeFunc()
+		})
+	}
+}
+
+func saveAndGetCheckpoint(cs eventsourcing.CheckpointStore) error {
+	err := cs.Save(context.Background(), "projection", 10)
+	if err != nil {
+		return err
+	}
+	err = cs.Save(context.Background(), "other", 20)
+	if err != nil {
+		return err
+	}
+
+	position, err := cs.Get(context.Background(), "projection")
+	if err != nil {
+		return err
+	}
+	if position != 10 {
+		return fmt.Errorf("exp position 10 got %d", position)
+	}
+
+	position, err = cs.Get(context.Background(), "other")
+	if err != nil {
+		return err
+	}
+	if position != 20 {
+		return fmt.Errorf("exp position 20 got %d", position)
+	}
+	return nil
+}
+
+func overwriteCheckpoint(cs eventsourcing.CheckpointStore) error {
+	err := cs.Save(context.Background(), "projection", 10)
+	if err != nil {
+		return err
+	}
+	err = cs.Save(context.Background(), "projection", 11)
+	if err != nil {
+		return err
+	}
+
+	position, err := cs.Get(context.Background(), "projection")
+	if err != nil {
+		return err
+	}
+	if position != 11 {
+		return fmt.Errorf("exp position 11 got %d", position)
+	}
+	return nil
+}
+
+func getNoneExistingCheckpoint(cs eventsourcing.CheckpointStore) error {
+	_, err := cs.Get(context.Background(), "none_existing")
+	if !errors.Is(err, eventsourcing.ErrCheckpointNotFound) {
+		return fmt.Errorf("expected ErrCheckpointNotFound got %v", err)
+	}
+	return nil
+}
+
+func deleteCheckpoint(cs eventsourcing.CheckpointStore) error {
+	err := cs.Save(context.Background(), "projection", 10)
+	if err != nil {
+		return err
+	}
+	err = cs.Save(context.Background(), "other", 20)
+	if err != nil {
+		return err
+	}
+	err = cs.Delete(context.Background(), "projection")
+	if err != nil {
+		return err
+	}
+	_, err = cs.Get(context.Background(), "projection")
+	if !errors.Is(err, eventsourcing.ErrCheckpointNotFound) {
+		return fmt.Errorf("expected ErrCheckpointNotFound got %v", err)
+	}
+	// the other checkpoint is kept
+	position, err := cs.Get(context.Background(), "other")
+	if err != nil {
+		return err
+	}
+	if position != 20 {
+		return fmt.Errorf("exp position 20 got %d", position)
+	}
+	return nil
+}
+
+func deleteNoneExistingCheckpoint(cs eventsourcing.CheckpointStore) error {
+	err := cs.Delete(context.Background(), "none_existing")
+	if !errors.Is(err, eventsourcing.ErrCheckpointNotFound) {
+		return fmt.Errorf("expected ErrCheckpointNotFound got %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package eventsourcingtest
+
+import (
+	"sync"
+	"time"
+)
+
+// FakeClock is a clock that only moves when it's advanced, making time dependent code testable without sleeping
+type FakeClock struct {
+	lock    sync.Mutex
+	now     time.Time
+	waiters []waiter
+}
+
+// waiter is a channel that receives the time when the clock reaches at
+type waiter struct {
+	at time.Time
+	c  chan time.Time
+}
+
+// NewFakeClock creates a fake clock set to now
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+// Now returns the time of the clock
+func (c *FakeClock) Now() time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.now
+}
+
+// After returns a channel that receives the time when the clock is advanced the duration
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	w := waiter{at: c.now.Add(d), c: make(chan time.Time, 1)}
+	if d <= 0 {
+		w.c <- c.now
+		return w.c
+	}
+	c.waiters = append(c.waiters, w)
+	return w.c
+}
+
+// Advance moves the clock forward and fires the waiters that are due
+func (c *FakeClock) Advance(d time.Duration) {
+	c.Set(c.Now().Add(d))
+}
+
+// Set sets the time of the clock and fires the waiters that are due
+func (c *FakeClock) Set(now time.Time) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.now = now
+	waiters := make([]waiter, 0, len(c.waiters))
+	for _, w := range c.waiters {
+		if w.at.After(now) {
+			waiters = append(waiters, w)
+			continue
+		}
+		w.c <- now
+	}
+	c.waiters = waiters
+}
+
+// Waiters returns the number of waiters that are not fired, e.g. to know that a projection is waiting for the pace
+func (c *FakeClock) Waiters() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return len(c.waiters)
+}
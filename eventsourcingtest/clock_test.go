@@ -0,0 +1,37 @@
+package eventsourcingtest_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hallgren/eventsourcing/eventsourcingtest"
+)
+
+func TestFakeClock(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	clock := eventsourcingtest.NewFakeClock(now)
+
+	c := clock.After(time.Minute)
+	clock.Advance(time.Second * 59)
+	select {
+	case <-c:
+		t.Fatal("expected the waiter not to fire before the duration")
+	default:
+	}
+	if clock.Waiters() != 1 {
+		t.Fatalf("expected one waiter got %d", clock.Waiters())
+	}
+
+	clock.Advance(time.Second)
+	select {
+	case fired := <-c:
+		if !fired.Equal(now.Add(time.Minute)) {
+			t.Fatalf("expected fired at %v got %v", now.Add(time.Minute), fired)
+		}
+	default:
+		t.Fatal("expected the waiter to fire")
+	}
+	if clock.Waiters() != 0 {
+		t.Fatalf("expected no waiters got %d", clock.Waiters())
+	}
+}
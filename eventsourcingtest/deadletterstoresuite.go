@@ -0,0 +1,121 @@
+package eventsourcingtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/core"
+)
+
+type deadletterstoreFunc = func() (eventsourcing.DeadLetterStore, func(), error)
+
+func TestDeadLetterStore(t *testing.T, dsFunc deadletterstoreFunc) {
+	tests := []struct {
+		title string
+		run   func(ds eventsourcing.DeadLetterStore) error
+	}{
+		{"should park and list dead letters", parkAndListDeadLetters},
+		{"should remove dead letter", removeDeadLetter},
+		{"should get error when removing none existing dead letter", removeNoneExistingDeadLetter},
+	}
+
+	for _, test := range tests {
+		t.Run(test.title, func(t *testing.T) {
+			ds, closeFunc, err := dsFunc()
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = test.run(ds)
+			if err != nil {
+				// make use of t.Error instead of t.Fatal to make sure the closeFunc is executed
+				t.Error(err)
+			}
+			closeFunc()
+		})
+	}
+}
+
+func deadLetter(projection string, globalVersion core.Version) eventsourcing.DeadLetter {
+	event := core.Event{
+		AggregateID:   "123",
+		Version:       1,
+		GlobalVersion: globalVersion,
+		AggregateType: "Person",
+		Timestamp:     time.Now().UTC(),
+		Reason:        "Born",
+		Data:          []byte(`{"name":"kalle"}`),
+		Metadata:      []byte(`{}`),
+	}
+	return eventsourcing.DeadLetter{
+		Projection: projection,
+		Event:      event,
+		Error:      "callback error",
+		Timestamp:  time.Now().UTC(),
+	}
+}
+
+func parkAndListDeadLetters(ds eventsourcing.DeadLetterStore) error {
+	ctx := context.Background()
+	for _, d := range []eventsourcing.DeadLetter{deadLetter("projection", 2), deadLetter("projection", 1), deadLetter("other", 3)} {
+		err := ds.Park(ctx, d)
+		if err != nil {
+			return err
+		}
+	}
+
+	deadLetters, err := ds.List(ctx, "projection")
+	if err != nil {
+		return err
+	}
+	if len(deadLetters) != 2 {
+		return fmt.Errorf("exp 2 dead letters got %d", len(deadLetters))
+	}
+	// the dead letters should be in global version order
+	if deadLetters[0].Event.GlobalVersion != 1 || deadLetters[1].Event.GlobalVersion != 2 {
+		return fmt.Errorf("dead letters in wrong order")
+	}
+	d := deadLetters[0]
+	exp := deadLetter("projection", 1)
+	if d.Projection != exp.Projection || d.Error != exp.Error {
+		return fmt.Errorf("exp dead letter %v got %v", exp, d)
+	}
+	if d.Event.Reason != exp.Event.Reason || d.Event.AggregateType != exp.Event.AggregateType || d.Event.Version != exp.Event.Version {
+		return fmt.Errorf("exp event %v got %v", exp.Event, d.Event)
+	}
+	if string(d.Event.Data) != string(exp.Event.Data) || string(d.Event.Metadata) != string(exp.Event.Metadata) {
+		return fmt.Errorf("exp event data %s got %s", exp.Event.Data, d.Event.Data)
+	}
+	return nil
+}
+
+func removeDeadLetter(ds eventsourcing.DeadLetterStore) error {
+	ctx := context.Background()
+	err := ds.Park(ctx, deadLetter("projection", 1))
+	if err != nil {
+		return err
+	}
+	err = ds.Remove(ctx, "projection", 1)
+	if err != nil {
+		return err
+	}
+	deadLetters, err := ds.List(ctx, "projection")
+	if err != nil {
+		return err
+	}
+	if len(deadLetters) != 0 {
+		return fmt.Errorf("exp no dead letters got %d", len(deadLetters))
+	}
+	return nil
+}
+
+func removeNoneExistingDeadLetter(ds eventsourcing.DeadLetterStore) error {
+	err := ds.Remove(context.Background(), "projection", 1)
+	if !errors.Is(err, eventsourcing.ErrDeadLetterNotFound) {
+		return fmt.Errorf("expected ErrDeadLetterNotFound got %v", err)
+	}
+	return nil
+}
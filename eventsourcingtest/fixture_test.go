@@ -0,0 +1,130 @@
+package eventsourcingtest_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/eventsourcingtest"
+)
+
+type Person struct {
+	eventsourcing.AggregateRoot
+	Name string
+	Age  int
+}
+
+type Born struct {
+	Name string
+}
+
+type AgedOneYear struct{}
+
+var errBlankName = errors.New("name can't be blank")
+
+func CreatePerson(name string) (*Person, error) {
+	if name == "" {
+		return nil, errBlankName
+	}
+	person := &Person{}
+	person.TrackChange(person, &Born{Name: name})
+	return person, nil
+}
+
+func (person *Person) GrowOlder() {
+	person.TrackChange(person, &AgedOneYear{})
+}
+
+func (person *Person) Register(f eventsourcing.RegisterFunc) {
+	f(&Born{}, &AgedOneYear{})
+}
+
+func (person *Person) Transition(event eventsourcing.Event) {
+	switch e := event.Data().(type) {
+	case *Born:
+		person.Age = 0
+		person.Name = e.Name
+	case *AgedOneYear:
+		person.Age++
+	}
+}
+
+// recorder records the failure instead of failing the test
+type recorder struct {
+	testing.TB
+	message string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Fatalf(format string, args ...interface{}) {
+	r.message = fmt.Sprintf(format, args...)
+}
+
+func TestAggregateFixture(t *testing.T) {
+	f := eventsourcingtest.NewAggregateFixture(t, &Person{}).
+		Given(&Born{Name: "kalle"}).
+		When(func(p *Person) error {
+			p.GrowOlder()
+			return nil
+		})
+	f.Then(&AgedOneYear{})
+	if f.Aggregate().Age != 1 || f.Aggregate().Version() != 2 {
+		t.Fatalf("wrong aggregate state %v", f.Aggregate())
+	}
+
+	eventsourcingtest.NewAggregateFixture(t, &Person{}).
+		WhenCreate(func() (*Person, error) {
+			return CreatePerson("kalle")
+		}).
+		Then(Born{Name: "kalle"})
+
+	eventsourcingtest.NewAggregateFixture(t, &Person{}).
+		WhenCreate(func() (*Person, error) {
+			return CreatePerson("")
+		}).
+		ThenError(errBlankName)
+}
+
+func TestAggregateFixtureDiff(t *testing.T) {
+	r := &recorder{TB: t}
+	eventsourcingtest.NewAggregateFixture(r, &Person{}).
+		WhenCreate(func() (*Person, error) {
+			return CreatePerson("kalle")
+		}).
+		Then(&Born{Name: "anka"}, &AgedOneYear{})
+
+	expected := "events differ\nexpected:\n  1: Born {Name:anka}\n  2: AgedOneYear {}\ngot:\n  1: Born {Name:kalle}"
+	if r.message != expected {
+		t.Fatalf("expected message:\n%s\ngot:\n%s", expected, r.message)
+	}
+}
+
+func TestProjectionFixture(t *testing.T) {
+	names := make([]string, 0)
+	eventsourcingtest.NewProjectionFixture(t, &Person{}).
+		Given(&Person{}, "1", &Born{Name: "kalle"}, &AgedOneYear{}).
+		Given(&Person{}, "2", &Born{Name: "anka"}).
+		When(func(e eventsourcing.Event) error {
+			switch data := e.Data().(type) {
+			case *Born:
+				names = append(names, data.Name)
+			}
+			return nil
+		}).
+		Then(func(t testing.TB) {
+			if strings.Join(names, ",") != "kalle,anka" {
+				t.Fatalf("expected kalle and anka got %v", names)
+			}
+		})
+
+	errProjection := errors.New("projection error")
+	eventsourcingtest.NewProjectionFixture(t, &Person{}).
+		Given(&Person{}, "1", &Born{Name: "kalle"}).
+		When(func(e eventsourcing.Event) error {
+			return errProjection
+		}).
+		ThenError(errProjection)
+}
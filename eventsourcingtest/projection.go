@@ -0,0 +1,91 @@
+package eventsourcingtest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/eventstore/memory"
+)
+
+// ProjectionFixture tests a projection by storing events in an in-memory event store, running the projection
+// callback on them and asserting the result.
+type ProjectionFixture struct {
+	t        testing.TB
+	es       *memory.Memory
+	register *eventsourcing.Register
+	versions map[string]core.Version
+	err      error
+}
+
+// NewProjectionFixture creates a fixture where the events of the aggregates can be handled by the projection
+func NewProjectionFixture(t testing.TB, aggregates ...Aggregate) *ProjectionFixture {
+	register := eventsourcing.NewRegister()
+	for _, a := range aggregates {
+		register.Register(a)
+	}
+	return &ProjectionFixture{
+		t:        t,
+		es:       memory.Create(),
+		register: register,
+		versions: make(map[string]core.Version),
+	}
+}
+
+// Given stores the event data as events on the aggregate with the id
+func (f *ProjectionFixture) Given(a Aggregate, id string, data ...interface{}) *ProjectionFixture {
+	f.t.Helper()
+	aggregateType := typeName(a)
+	key := aggregateType + "_" + id
+	encoder := eventsourcing.EncoderJSON{}
+	events := make([]core.Event, 0, len(data))
+	for _, d := range data {
+		b, err := encoder.Serialize(d)
+		if err != nil {
+			f.t.Fatal(err)
+		}
+		f.versions[key]++
+		events = append(events, core.Event{
+			AggregateID:   id,
+			Version:       f.versions[key],
+			AggregateType: aggregateType,
+			Reason:        typeName(d),
+			Timestamp:     time.Now().UTC(),
+			Data:          b,
+		})
+	}
+	err := f.es.Save(events)
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	return f
+}
+
+// When runs the projection callback on the stored events
+func (f *ProjectionFixture) When(callbackF func(e eventsourcing.Event) error) *ProjectionFixture {
+	ph := eventsourcing.NewProjectionHandler(f.register, eventsourcing.EncoderJSON{})
+	p := ph.ProjectionFrom(f.es.AllFrom(100), callbackF)
+	f.err = p.RunToEnd(context.Background()).Error
+	return f
+}
+
+// Then asserts that the projection did not return an error and calls the check func, e.g. to assert the read-model
+func (f *ProjectionFixture) Then(check func(t testing.TB)) {
+	f.t.Helper()
+	if f.err != nil {
+		f.t.Fatalf("expected no error got %v", f.err)
+		return
+	}
+	check(f.t)
+}
+
+// ThenError asserts that the projection returned the expected error
+func (f *ProjectionFixture) ThenError(expected error) {
+	f.t.Helper()
+	if !errors.Is(f.err, expected) {
+		f.t.Fatalf("expected error %v got %v", expected, f.err)
+	}
+}
@@ -0,0 +1,199 @@
+package eventsourcingtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hallgren/eventsourcing"
+)
+
+type schedulestoreFunc = func() (eventsourcing.ScheduleStore, func(), error)
+
+func TestScheduleStore(t *testing.T, ssFunc schedulestoreFunc) {
+	tests := []struct {
+		title string
+		run   func(ss eventsourcing.ScheduleStore) error
+	}{
+		{"should get due items in due order", dueItems},
+		{"should limit due items", limitDueItems},
+		{"should replace item with same id", replaceScheduledItem},
+		{"should remove item", removeScheduledItem},
+		{"should get error when removing none existing item", removeNoneExistingScheduledItem},
+		{"should remove item if due", removeIfDueScheduledItem},
+	}
+
+	for _, test := range tests {
+		t.Run(test.title, func(t *testing.T) {
+			ss, closeFunc, err := ssFunc()
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = test.run(ss)
+			if err != nil {
+				// make use of t.Error instead of t.Fatal to make sure the closeFunc is executed
+				t.Error(err)
+			}
+			closeFunc()
+		})
+	}
+}
+
+var scheduleNow = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func scheduledItem(id string, due time.Time) eventsourcing.ScheduledItem {
+	return eventsourcing.ScheduledItem{
+		ID:   id,
+		Due:  due,
+		Type: "ExpireReservation",
+		Data: []byte(fmt.Sprintf(`{"id":"%s"}`, id)),
+	}
+}
+
+func dueItems(ss eventsourcing.ScheduleStore) error {
+	ctx := context.Background()
+	items := []eventsourcing.ScheduledItem{
+		scheduledItem("later", scheduleNow.Add(time.Minute)),
+		scheduledItem("second", scheduleNow.Add(-time.Second)),
+		scheduledItem("first", scheduleNow.Add(-time.Minute)),
+		scheduledItem("now", scheduleNow),
+	}
+	for _, item := range items {
+		err := ss.Schedule(ctx, item)
+		if err != nil {
+			return err
+		}
+	}
+	due, err := ss.Due(ctx, scheduleNow, 10)
+	if err != nil {
+		return err
+	}
+	if len(due) != 3 {
+		return fmt.Errorf("expected 3 due items got %d", len(due))
+	}
+	for i, id := range []string{"first", "second", "now"} {
+		if due[i].ID != id {
+			return fmt.Errorf("expected item %s at position %d got %s", id, i, due[i].ID)
+		}
+	}
+	item := due[0]
+	if !item.Due.Equal(scheduleNow.Add(-time.Minute)) || item.Type != "ExpireReservation" || string(item.Data) != `{"id":"first"}` {
+		return fmt.Errorf("wrong scheduled item %v", item)
+	}
+	return nil
+}
+
+func limitDueItems(ss eventsourcing.ScheduleStore) error {
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		err := ss.Schedule(ctx, scheduledItem(fmt.Sprintf("%d", i), scheduleNow.Add(-time.Duration(i)*time.Second)))
+		if err != nil {
+			return err
+		}
+	}
+	due, err := ss.Due(ctx, scheduleNow, 2)
+	if err != nil {
+		return err
+	}
+	if len(due) != 2 {
+		return fmt.Errorf("expected 2 due items got %d", len(due))
+	}
+	if due[0].ID != "2" || due[1].ID != "1" {
+		return fmt.Errorf("expected the items with the earliest due time got %s and %s", due[0].ID, due[1].ID)
+	}
+	return nil
+}
+
+func replaceScheduledItem(ss eventsourcing.ScheduleStore) error {
+	ctx := context.Background()
+	err := ss.Schedule(ctx, scheduledItem("1", scheduleNow.Add(-time.Minute)))
+	if err != nil {
+		return err
+	}
+	// reschedule the item to the future
+	err = ss.Schedule(ctx, scheduledItem("1", scheduleNow.Add(time.Minute)))
+	if err != nil {
+		return err
+	}
+	due, err := ss.Due(ctx, scheduleNow, 10)
+	if err != nil {
+		return err
+	}
+	if len(due) != 0 {
+		return fmt.Errorf("expected no due items got %d", len(due))
+	}
+	due, err = ss.Due(ctx, scheduleNow.Add(time.Hour), 10)
+	if err != nil {
+		return err
+	}
+	if len(due) != 1 {
+		return fmt.Errorf("expected one due item got %d", len(due))
+	}
+	return nil
+}
+
+func removeScheduledItem(ss eventsourcing.ScheduleStore) error {
+	ctx := context.Background()
+	err := ss.Schedule(ctx, scheduledItem("1", scheduleNow))
+	if err != nil {
+		return err
+	}
+	err = ss.Remove(ctx, "1")
+	if err != nil {
+		return err
+	}
+	due, err := ss.Due(ctx, scheduleNow, 10)
+	if err != nil {
+		return err
+	}
+	if len(due) != 0 {
+		return fmt.Errorf("expected no due items got %d", len(due))
+	}
+	return nil
+}
+
+func removeNoneExistingScheduledItem(ss eventsourcing.ScheduleStore) error {
+	err := ss.Remove(context.Background(), "none existing")
+	if !errors.Is(err, eventsourcing.ErrScheduledItemNotFound) {
+		return fmt.Errorf("expected ErrScheduledItemNotFound got %v", err)
+	}
+	return nil
+}
+
+func removeIfDueScheduledItem(ss eventsourcing.ScheduleStore) error {
+	ctx := context.Background()
+	err := ss.Schedule(ctx, scheduledItem("1", scheduleNow))
+	if err != nil {
+		return err
+	}
+	// the item is rescheduled after it was fetched as due
+	err = ss.Schedule(ctx, scheduledItem("1", scheduleNow.Add(time.Minute)))
+	if err != nil {
+		return err
+	}
+	err = ss.RemoveIfDue(ctx, "1", scheduleNow)
+	if !errors.Is(err, eventsourcing.ErrScheduledItemNotFound) {
+		return fmt.Errorf("expected ErrScheduledItemNotFound got %v", err)
+	}
+	due, err := ss.Due(ctx, scheduleNow.Add(time.Hour), 10)
+	if err != nil {
+		return err
+	}
+	if len(due) != 1 {
+		return fmt.Errorf("expected the rescheduled item to be kept got %d items", len(due))
+	}
+	err = ss.RemoveIfDue(ctx, "1", scheduleNow.Add(time.Minute))
+	if err != nil {
+		return err
+	}
+	due, err = ss.Due(ctx, scheduleNow.Add(time.Hour), 10)
+	if err != nil {
+		return err
+	}
+	if len(due) != 0 {
+		return fmt.Errorf("expected no due items got %d", len(due))
+	}
+	return nil
+}
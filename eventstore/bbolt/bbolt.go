@@ -3,7 +3,6 @@ package bbolt
 import (
 	"context"
 	"encoding/binary"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"time"
@@ -18,7 +17,19 @@ const (
 
 // BBolt is the eventstore handler
 type BBolt struct {
-	db *bbolt.DB // The bbolt db where we store everything
+	db          *bbolt.DB // The bbolt db where we store everything
+	deduplicate bool      // store the events once in the global event order with pointers in the aggregate buckets
+}
+
+// Option configures the bbolt event store
+type Option func(e *BBolt)
+
+// WithDeduplication stores each event once, in the global event order, and a pointer to it in the aggregate bucket.
+// It halves the disk usage but makes Get seek each event when the events of many aggregates are interleaved.
+func WithDeduplication() Option {
+	return func(e *BBolt) {
+		e.deduplicate = true
+	}
 }
 
 type boltEvent struct {
@@ -34,7 +45,7 @@ type boltEvent struct {
 
 // MustOpenBBolt opens the event stream found in the given file. If the file is not found it will be created and
 // initialized. Will panic if it has problems persisting the changes to the filesystem.
-func MustOpenBBolt(dbFile string) *BBolt {
+func MustOpenBBolt(dbFile string, options ...Option) *BBolt {
 	db, err := bbolt.Open(dbFile, 0600, &bbolt.Options{
 		Timeout: 1 * time.Second,
 	})
@@ -52,9 +63,13 @@ func MustOpenBBolt(dbFile string) *BBolt {
 	if err != nil {
 		panic(err)
 	}
-	return &BBolt{
+	e := &BBolt{
 		db: db,
 	}
+	for _, option := range options {
+		option(e)
+	}
+	return e
 }
 
 // Save an aggregate (its events)
@@ -85,16 +100,11 @@ func (e *BBolt) Save(events []core.Event) error {
 		evBucket = tx.Bucket(bucketRef)
 	}
 
+	// the events are stored with the aggregate version as key
 	currentVersion := uint64(0)
-	cursor := evBucket.Cursor()
-	k, obj := cursor.Last()
+	k, _ := evBucket.Cursor().Last()
 	if k != nil {
-		lastEvent := boltEvent{}
-		err := json.Unmarshal(obj, &lastEvent)
-		if err != nil {
-			return errors.New(fmt.Sprintf("could not serialize event, %v", err))
-		}
-		currentVersion = lastEvent.Version
+		currentVersion = binary.BigEndian.Uint64(k)
 	}
 
 	// Make sure no other has saved event to the same aggregate concurrently
@@ -116,7 +126,6 @@ func (e *BBolt) Save(events []core.Event) error {
 
 		// We need to establish a global event order that spans over all buckets. This is so that we can be
 		// able to play the event (or send) them in the order that they was entered into this database.
-		// The global sequence bucket contains an ordered line of pointer to all events on the form bucket_name:seq_num
 		globalSequence, err = globalBucket.NextSequence()
 		if err != nil {
 			return errors.New("could not get next sequence for global bucket")
@@ -134,18 +143,23 @@ func (e *BBolt) Save(events []core.Event) error {
 			Data:          event.Data,
 		}
 
-		value, err := json.Marshal(bEvent)
+		value, err := encodeEvent(bEvent)
 		if err != nil {
 			return errors.New(fmt.Sprintf("could not serialize event, %v", err))
 		}
 
-		err = evBucket.Put(itob(sequence), value)
+		// with deduplication the aggregate bucket holds a pointer to the event in the global sequence bucket
+		aggregateValue := value
+		if e.deduplicate {
+			aggregateValue = encodePointer(itob(globalSequence))
+		}
+		err = evBucket.Put(itob(sequence), aggregateValue)
 		if err != nil {
 			return errors.New(fmt.Sprintf("could not save event %#v in bucket", event))
 		}
 		err = globalBucket.Put(itob(globalSequence), value)
 		if err != nil {
-			return errors.New(fmt.Sprintf("could not save global sequence pointer for %#v", string(bucketRef)))
+			return errors.New(fmt.Sprintf("could not save event in the global sequence for %#v", string(bucketRef)))
 		}
 
 		// override the event in the slice exposing the GlobalVersion to the caller
@@ -183,6 +197,20 @@ func (e *BBolt) All(start uint64) (core.Iterator, error) {
 	return &iterator{tx: tx, cursor: cursor, startPosition: position(core.Version(start))}, nil
 }
 
+// Head returns the global version of the last saved event
+func (e *BBolt) Head() (core.Version, error) {
+	var head uint64
+	err := e.db.View(func(tx *bbolt.Tx) error {
+		globalBucket := tx.Bucket([]byte(globalEventOrderBucketName))
+		if globalBucket == nil {
+			return errors.New("global bucket not found")
+		}
+		head = globalBucket.Sequence()
+		return nil
+	})
+	return core.Version(head), err
+}
+
 // Close closes the event stream and the underlying database
 func (e *BBolt) Close() error {
 	return e.db.Close()
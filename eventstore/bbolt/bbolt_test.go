@@ -1,12 +1,15 @@
 package bbolt_test
 
 import (
+	"bytes"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/hallgren/eventsourcing/core"
 	"github.com/hallgren/eventsourcing/core/testsuite"
 	"github.com/hallgren/eventsourcing/eventstore/bbolt"
+	bolt "go.etcd.io/bbolt"
 )
 
 func TestSuite(t *testing.T) {
@@ -20,3 +23,77 @@ func TestSuite(t *testing.T) {
 	}
 	testsuite.Test(t, f)
 }
+
+func TestSuiteDeduplication(t *testing.T) {
+	f := func() (core.EventStore, func(), error) {
+		dbFile := "bolt_deduplication.db"
+		es := bbolt.MustOpenBBolt(dbFile, bbolt.WithDeduplication())
+		return es, func() {
+			es.Close()
+			os.Remove(dbFile)
+		}, nil
+	}
+	testsuite.Test(t, f)
+}
+
+func TestHead(t *testing.T) {
+	dbFile := "head.db"
+	es := bbolt.MustOpenBBolt(dbFile)
+	defer os.Remove(dbFile)
+	defer es.Close()
+
+	head, err := es.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if head != 0 {
+		t.Fatalf("expected head 0 on empty event store got %d", head)
+	}
+
+	events := []core.Event{
+		{AggregateID: "1", Version: 1, AggregateType: "Person", Reason: "Born", Data: []byte("{}")},
+		{AggregateID: "1", Version: 2, AggregateType: "Person", Reason: "AgedOneYear", Data: []byte("{}")},
+	}
+	err = es.Save(events)
+	if err != nil {
+		t.Fatal(err)
+	}
+	head, err = es.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if head != 2 {
+		t.Fatalf("expected head 2 got %d", head)
+	}
+}
+
+func TestSaveStoresEventOnce(t *testing.T) {
+	dbFile := filepath.Join(t.TempDir(), "once.db")
+	es := bbolt.MustOpenBBolt(dbFile, bbolt.WithDeduplication())
+	events := []core.Event{
+		{AggregateID: "1", Version: 1, AggregateType: "Person", Reason: "Born", Data: []byte(`{"name":"kalle"}`)},
+		{AggregateID: "1", Version: 2, AggregateType: "Person", Reason: "AgedOneYear", Data: []byte("{}")},
+	}
+	err := es.Save(events)
+	if err != nil {
+		t.Fatal(err)
+	}
+	es.Close()
+
+	db, err := bolt.Open(dbFile, 0600, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	err = db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte("Person_1")).ForEach(func(k, v []byte) error {
+			if bytes.Contains(v, []byte("Born")) || bytes.Contains(v, []byte("AgedOneYear")) {
+				t.Fatalf("expected a pointer to the event in the aggregate bucket got %s", v)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -1,7 +1,8 @@
 package bbolt
 
 import (
-	"encoding/json"
+	"bytes"
+	"encoding/binary"
 	"errors"
 	"fmt"
 
@@ -14,6 +15,9 @@ type iterator struct {
 	cursor        *bbolt.Cursor
 	startPosition []byte
 	value         []byte
+	// globalCursor is positioned at the last event in the global event order that a pointer was resolved to
+	globalCursor *bbolt.Cursor
+	globalKey    []byte
 }
 
 // Close closes the iterator
@@ -37,10 +41,13 @@ func (i *iterator) Next() bool {
 
 // Next return the next event
 func (i *iterator) Value() (core.Event, error) {
-	bEvent := boltEvent{}
-	err := json.Unmarshal(i.value, &bEvent)
+	value, err := i.resolve(i.value)
 	if err != nil {
-		return core.Event{}, errors.New(fmt.Sprintf("could not deserialize event, %v", err))
+		return core.Event{}, err
+	}
+	bEvent, err := decodeEvent(value)
+	if err != nil {
+		return core.Event{}, err
 	}
 
 	event := core.Event{
@@ -55,3 +62,32 @@ func (i *iterator) Value() (core.Event, error) {
 	}
 	return event, nil
 }
+
+// resolve returns the event in the global event order that the value points to or the value if it is not a pointer
+func (i *iterator) resolve(value []byte) ([]byte, error) {
+	key, ok := decodePointer(value)
+	if !ok {
+		return value, nil
+	}
+	if i.globalCursor == nil {
+		bucket := i.tx.Bucket([]byte(globalEventOrderBucketName))
+		if bucket == nil {
+			return nil, errors.New("global bucket not found")
+		}
+		i.globalCursor = bucket.Cursor()
+	}
+	// consecutive events of an aggregate are often consecutive in the global event order, the cursor then moves to
+	// the next event instead of seeking it
+	var k, event []byte
+	if i.globalKey != nil && binary.BigEndian.Uint64(key) == binary.BigEndian.Uint64(i.globalKey)+1 {
+		k, event = i.globalCursor.Next()
+	} else {
+		k, event = i.globalCursor.Seek(key)
+	}
+	if !bytes.Equal(k, key) {
+		i.globalKey = nil
+		return nil, fmt.Errorf("could not find event %d in the global event order", binary.BigEndian.Uint64(key))
+	}
+	i.globalKey = k
+	return event, nil
+}
@@ -0,0 +1,140 @@
+package bbolt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	"go.etcd.io/bbolt"
+)
+
+// migrateBatchSize is the number of values converted in one write transaction by Migrate
+const migrateBatchSize = 1000
+
+// Migrate converts the events stored in the JSON format to the binary record format. With deduplication it replaces
+// the copies of the events in the aggregate buckets with pointers to the events in the global event order, otherwise
+// it replaces the pointers with copies. Databases in both layouts and formats are readable and Migrate is optional.
+// The values are converted in batches, each in its own transaction, and an interrupted migration can be run again.
+func (e *BBolt) Migrate() error {
+	var buckets [][]byte
+	err := e.db.View(func(tx *bbolt.Tx) error {
+		return tx.ForEach(func(name []byte, _ *bbolt.Bucket) error {
+			buckets = append(buckets, append([]byte{}, name...))
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+	for _, name := range buckets {
+		switch {
+		case string(name) == globalEventOrderBucketName:
+			err = e.migrateBucket(name, toRecord)
+		case e.deduplicate:
+			err = e.migrateBucket(name, toPointer)
+		default:
+			err = e.migrateBucket(name, toCopy)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// toRecord converts the JSON event to the binary record format
+func toRecord(_ *bbolt.Tx, value []byte) ([]byte, bool, error) {
+	if !isJSON(value) {
+		return nil, false, nil
+	}
+	event, err := decodeEvent(value)
+	if err != nil {
+		return nil, false, err
+	}
+	value, err = encodeEvent(event)
+	return value, true, err
+}
+
+// toPointer converts the event in the aggregate bucket to a pointer to the event in the global event order, where
+// the event is stored with the global version as key
+func toPointer(_ *bbolt.Tx, value []byte) ([]byte, bool, error) {
+	if _, ok := decodePointer(value); ok {
+		return nil, false, nil
+	}
+	event, err := decodeEvent(value)
+	if err != nil {
+		return nil, false, err
+	}
+	return encodePointer(itob(event.GlobalVersion)), true, nil
+}
+
+// toCopy converts the pointer in the aggregate bucket to a copy of the event in the global event order and the JSON
+// event to the binary record format
+func toCopy(tx *bbolt.Tx, value []byte) ([]byte, bool, error) {
+	key, ok := decodePointer(value)
+	if !ok {
+		return toRecord(tx, value)
+	}
+	event := tx.Bucket([]byte(globalEventOrderBucketName)).Get(key)
+	if event == nil {
+		return nil, false, fmt.Errorf("could not find event %d in the global event order", binary.BigEndian.Uint64(key))
+	}
+	if value, ok, err := toRecord(tx, event); ok || err != nil {
+		return value, ok, err
+	}
+	return append([]byte{}, event...), true, nil
+}
+
+// migrateBucket replaces the values in the bucket with the values from the convert func. The convert func returns
+// false if the value should be kept.
+func (e *BBolt) migrateBucket(name []byte, convert func(tx *bbolt.Tx, value []byte) ([]byte, bool, error)) error {
+	var from []byte
+	for {
+		done := true
+		err := e.db.Update(func(tx *bbolt.Tx) error {
+			bucket := tx.Bucket(name)
+			if bucket == nil {
+				return nil
+			}
+			type change struct {
+				key   []byte
+				value []byte
+			}
+			changes := make([]change, 0, migrateBatchSize)
+			cursor := bucket.Cursor()
+			k, v := cursor.First()
+			if from != nil {
+				k, v = cursor.Seek(from)
+				if bytes.Equal(k, from) {
+					k, v = cursor.Next()
+				}
+			}
+			for n := 0; k != nil; k, v = cursor.Next() {
+				if n == migrateBatchSize {
+					done = false
+					break
+				}
+				n++
+				from = append([]byte{}, k...)
+				value, ok, err := convert(tx, v)
+				if err != nil {
+					return err
+				}
+				if ok {
+					changes = append(changes, change{key: from, value: value})
+				}
+			}
+			// the values are replaced after the iteration as the cursor is not valid after a put
+			for _, c := range changes {
+				err := bucket.Put(c.key, c.value)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil || done {
+			return err
+		}
+	}
+}
@@ -0,0 +1,308 @@
+package bbolt_test
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/eventstore/bbolt"
+	bolt "go.etcd.io/bbolt"
+)
+
+// legacyEvent is the event as it was stored in the JSON format
+type legacyEvent struct {
+	AggregateID   string
+	Version       uint64
+	GlobalVersion uint64
+	Reason        string
+	AggregateType string
+	Timestamp     time.Time
+	Data          []byte
+	Metadata      []byte
+}
+
+func itob(v uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, v)
+	return b
+}
+
+// legacyDB creates a database with the events of the aggregate stored in the JSON format
+func legacyDB(t testing.TB, id string, count int) string {
+	return interleavedDB(t, []string{id}, count)
+}
+
+// interleavedDB creates a database with count events on each aggregate stored in the JSON format. The events are
+// saved round robin over the aggregates which makes consecutive events in the global event order belong to different
+// aggregates.
+func interleavedDB(t testing.TB, ids []string, count int) string {
+	dbFile := filepath.Join(t.TempDir(), "legacy.db")
+	db, err := bolt.Open(dbFile, 0600, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	err = db.Update(func(tx *bolt.Tx) error {
+		global, err := tx.CreateBucket([]byte("global_event_order"))
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			_, err = tx.CreateBucket([]byte("Person_" + id))
+			if err != nil {
+				return err
+			}
+		}
+		for i := 0; i < count*len(ids); i++ {
+			id := ids[i%len(ids)]
+			aggregate := tx.Bucket([]byte("Person_" + id))
+			seq, _ := aggregate.NextSequence()
+			globalSeq, _ := global.NextSequence()
+			value, err := json.Marshal(legacyEvent{
+				AggregateID:   id,
+				Version:       seq,
+				GlobalVersion: globalSeq,
+				Reason:        "AgedOneYear",
+				AggregateType: "Person",
+				Timestamp:     time.Now().UTC(),
+				Data:          []byte(`{"age":1}`),
+				Metadata:      []byte(`{}`),
+			})
+			if err != nil {
+				return err
+			}
+			err = aggregate.Put(itob(seq), value)
+			if err != nil {
+				return err
+			}
+			err = global.Put(itob(globalSeq), value)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dbFile
+}
+
+// assertEvents asserts that the aggregate and the global order holds the events in version order
+func assertEvents(t *testing.T, es *bbolt.BBolt, id string, count int) {
+	t.Helper()
+	iter, err := es.Get(context.Background(), id, "Person", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	version := core.Version(0)
+	for iter.Next() {
+		event, err := iter.Value()
+		if err != nil {
+			t.Fatal(err)
+		}
+		version++
+		if event.Version != version || event.AggregateID != id || string(event.Data) != `{"age":1}` {
+			t.Fatalf("unexpected event %+v", event)
+		}
+	}
+	iter.Close()
+	if version != core.Version(count) {
+		t.Fatalf("expected %d events got %d", count, version)
+	}
+
+	iter, err = es.All(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	globalVersion := core.Version(0)
+	for iter.Next() {
+		event, err := iter.Value()
+		if err != nil {
+			t.Fatal(err)
+		}
+		globalVersion++
+		if event.GlobalVersion != globalVersion {
+			t.Fatalf("expected global version %d got %d", globalVersion, event.GlobalVersion)
+		}
+	}
+	iter.Close()
+	if globalVersion != core.Version(count) {
+		t.Fatalf("expected %d events in the global order got %d", count, globalVersion)
+	}
+}
+
+func TestReadJSONFormat(t *testing.T) {
+	es := bbolt.MustOpenBBolt(legacyDB(t, "123", 3))
+	defer es.Close()
+	assertEvents(t, es, "123", 3)
+
+	// the version of the aggregate is read from the JSON events
+	err := es.Save([]core.Event{{AggregateID: "123", Version: 3, AggregateType: "Person", Reason: "AgedOneYear", Data: []byte(`{"age":1}`)}})
+	if !errors.Is(err, core.ErrConcurrency) {
+		t.Fatalf("expected concurrency error got %v", err)
+	}
+	err = es.Save([]core.Event{{AggregateID: "123", Version: 4, AggregateType: "Person", Reason: "AgedOneYear", Data: []byte(`{"age":1}`)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEvents(t, es, "123", 4)
+}
+
+// aggregateValues returns the values in the aggregate bucket
+func aggregateValues(t *testing.T, dbFile, bucket string) [][]byte {
+	db, err := bolt.Open(dbFile, 0600, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	var values [][]byte
+	err = db.View(func(tx *bolt.Tx) error {
+		return tx.ForEach(func(name []byte, b *bolt.Bucket) error {
+			return b.ForEach(func(k, v []byte) error {
+				if v[0] == '{' {
+					t.Fatalf("expected no JSON events after the migration in bucket %s", name)
+				}
+				if string(name) == bucket {
+					values = append(values, append([]byte{}, v...))
+				}
+				return nil
+			})
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return values
+}
+
+func TestMigrate(t *testing.T) {
+	dbFile := legacyDB(t, "123", 2500)
+	es := bbolt.MustOpenBBolt(dbFile)
+	err := es.Migrate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the migration can be run again
+	err = es.Migrate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEvents(t, es, "123", 2500)
+	es.Close()
+
+	// the aggregate bucket holds copies of the events
+	for _, v := range aggregateValues(t, dbFile, "Person_123") {
+		if len(v) == 9 {
+			t.Fatalf("expected a copy of the event in the aggregate bucket got %v", v)
+		}
+	}
+}
+
+func TestMigrateDeduplication(t *testing.T) {
+	dbFile := legacyDB(t, "123", 2500)
+	es := bbolt.MustOpenBBolt(dbFile, bbolt.WithDeduplication())
+	err := es.Migrate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEvents(t, es, "123", 2500)
+	es.Close()
+
+	// the aggregate bucket holds pointers to the events in the global event order
+	for _, v := range aggregateValues(t, dbFile, "Person_123") {
+		if len(v) != 9 {
+			t.Fatalf("expected a pointer in the aggregate bucket got %v", v)
+		}
+	}
+
+	// the pointers are replaced with copies when migrating without deduplication
+	es = bbolt.MustOpenBBolt(dbFile)
+	err = es.Migrate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEvents(t, es, "123", 2500)
+	es.Close()
+	for _, v := range aggregateValues(t, dbFile, "Person_123") {
+		if len(v) == 9 {
+			t.Fatalf("expected a copy of the event in the aggregate bucket got %v", v)
+		}
+	}
+}
+
+func BenchmarkAll(b *testing.B) {
+	benchmarkLayouts(b, func(es *bbolt.BBolt, id string) (core.Iterator, error) {
+		return es.All(0)
+	})
+}
+
+func BenchmarkGet(b *testing.B) {
+	benchmarkLayouts(b, func(es *bbolt.BBolt, id string) (core.Iterator, error) {
+		return es.Get(context.Background(), id, "Person", 0)
+	})
+}
+
+// benchmarkLayouts compares reading the events in the layouts the database has had, on a single aggregate and on
+// aggregates with interleaved events in the global event order:
+//
+//   - json, the events and the copies in the global event order in the JSON format
+//   - copies, the events and the copies in the global event order in the binary record format
+//   - pointers, the events in the binary record format in the global event order and pointers in the aggregate buckets
+//     as stored with deduplication
+func benchmarkLayouts(b *testing.B, iterF func(es *bbolt.BBolt, id string) (core.Iterator, error)) {
+	ids := make([]string, 1000)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("%d", i)
+	}
+	fixtures := []struct {
+		name string
+		ids  []string
+		dbF  func(b *testing.B) string
+	}{
+		{"single", []string{"123"}, func(b *testing.B) string { return legacyDB(b, "123", 10000) }},
+		{"interleaved", ids, func(b *testing.B) string { return interleavedDB(b, ids, 10) }},
+	}
+	for _, fixture := range fixtures {
+		for _, layout := range []string{"json", "copies", "pointers"} {
+			b.Run(fixture.name+"/"+layout, func(b *testing.B) {
+				dbFile := fixture.dbF(b)
+				if layout != "json" {
+					var options []bbolt.Option
+					if layout == "pointers" {
+						options = append(options, bbolt.WithDeduplication())
+					}
+					es := bbolt.MustOpenBBolt(dbFile, options...)
+					err := es.Migrate()
+					es.Close()
+					if err != nil {
+						b.Fatal(err)
+					}
+				}
+				es := bbolt.MustOpenBBolt(dbFile)
+				defer es.Close()
+				id := fixture.ids[len(fixture.ids)/2]
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					iter, err := iterF(es, id)
+					if err != nil {
+						b.Fatal(err)
+					}
+					for iter.Next() {
+						_, err = iter.Value()
+						if err != nil {
+							b.Fatal(err)
+						}
+					}
+					iter.Close()
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package bbolt
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// recordFormatV1 is the first byte of an event stored in the binary record format. Events stored before the binary
+// format are JSON objects and starts with '{'.
+const recordFormatV1 byte = 1
+
+// pointerFormatV1 is the first byte of a pointer in an aggregate bucket, followed by the key of the event in the global
+// event order
+const pointerFormatV1 byte = 2
+
+// errUnknownFormat is returned when the stored value is not in a known format
+var errUnknownFormat = errors.New("unknown record format")
+
+// encodeEvent encodes the event in the binary record format. After the format byte the versions are stored as
+// uvarints followed by the length prefixed strings, timestamp, data and metadata. The timestamp keeps its location
+// offset in the same way as in the JSON format.
+func encodeEvent(event boltEvent) ([]byte, error) {
+	timestamp, err := event.Timestamp.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	// the format byte, two versions and six length prefixes followed by the values
+	size := 1 + 8*binary.MaxVarintLen64 + len(event.AggregateID) + len(event.AggregateType) + len(event.Reason) + len(timestamp) + len(event.Data) + len(event.Metadata)
+	b := make([]byte, 0, size)
+	b = append(b, recordFormatV1)
+	b = binary.AppendUvarint(b, event.Version)
+	b = binary.AppendUvarint(b, event.GlobalVersion)
+	b = appendBytes(b, []byte(event.AggregateID))
+	b = appendBytes(b, []byte(event.AggregateType))
+	b = appendBytes(b, []byte(event.Reason))
+	b = appendBytes(b, timestamp)
+	b = appendBytes(b, event.Data)
+	b = appendBytes(b, event.Metadata)
+	return b, nil
+}
+
+// decodeEvent decodes the event from the binary record format or from JSON
+func decodeEvent(value []byte) (boltEvent, error) {
+	if len(value) == 0 {
+		return boltEvent{}, errUnknownFormat
+	}
+	switch value[0] {
+	case '{':
+		event := boltEvent{}
+		err := json.Unmarshal(value, &event)
+		if err != nil {
+			return boltEvent{}, fmt.Errorf("could not deserialize event, %v", err)
+		}
+		return event, nil
+	case recordFormatV1:
+		return decodeRecordV1(value[1:])
+	}
+	return boltEvent{}, fmt.Errorf("%w: %d", errUnknownFormat, value[0])
+}
+
+// decodeRecordV1 decodes the event from the binary record format without the format byte
+func decodeRecordV1(b []byte) (boltEvent, error) {
+	r := reader{b: b}
+	event := boltEvent{
+		Version:       r.uvarint(),
+		GlobalVersion: r.uvarint(),
+		AggregateID:   string(r.bytes()),
+		AggregateType: string(r.bytes()),
+		Reason:        string(r.bytes()),
+	}
+	timestamp := r.bytes()
+	event.Data = r.bytes()
+	event.Metadata = r.bytes()
+	if r.err != nil {
+		return boltEvent{}, fmt.Errorf("could not deserialize event, %v", r.err)
+	}
+	err := event.Timestamp.UnmarshalBinary(timestamp)
+	if err != nil {
+		return boltEvent{}, fmt.Errorf("could not deserialize event timestamp, %v", err)
+	}
+	return event, nil
+}
+
+// encodePointer encodes the pointer to the event stored with the key in the global event order
+func encodePointer(key []byte) []byte {
+	return append([]byte{pointerFormatV1}, key...)
+}
+
+// decodePointer returns the key of the event in the global event order and false if the value is not a pointer
+func decodePointer(value []byte) (key []byte, ok bool) {
+	if len(value) != 9 || value[0] != pointerFormatV1 {
+		return nil, false
+	}
+	return value[1:], true
+}
+
+// isJSON returns true if the value is stored in the format used before the binary record format
+func isJSON(value []byte) bool {
+	return len(value) > 0 && value[0] == '{'
+}
+
+// appendBytes appends the length prefixed bytes
+func appendBytes(b, v []byte) []byte {
+	b = binary.AppendUvarint(b, uint64(len(v)))
+	return append(b, v...)
+}
+
+// reader reads the fields of a binary record, the first error is kept and stops the reading
+type reader struct {
+	b   []byte
+	err error
+}
+
+func (r *reader) uvarint() uint64 {
+	if r.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(r.b)
+	if n <= 0 {
+		r.err = errors.New("invalid uvarint")
+		return 0
+	}
+	r.b = r.b[n:]
+	return v
+}
+
+// bytes reads length prefixed bytes. Empty bytes are returned as nil.
+func (r *reader) bytes() []byte {
+	l := r.uvarint()
+	if r.err != nil {
+		return nil
+	}
+	if uint64(len(r.b)) < l {
+		r.err = errors.New("record too short")
+		return nil
+	}
+	if l == 0 {
+		return nil
+	}
+	// copy the bytes as the value from bbolt is only valid during the transaction
+	v := make([]byte, l)
+	copy(v, r.b[:l])
+	r.b = r.b[l:]
+	return v
+}
@@ -126,3 +126,21 @@ func (m *Memory) All(start core.Version, count uint64) func() (core.Iterator, er
 		return &iterator{events: events}, nil
 	}
 }
+
+// AllFrom iterate over count events in GlobalEvents order from the start position passed to the returned function
+func (m *Memory) AllFrom(count uint64) func(start core.Version) (core.Iterator, error) {
+	return func(start core.Version) (core.Iterator, error) {
+		events, err := m.globalEvents(start, count)
+		if err != nil {
+			return nil, err
+		}
+		return &iterator{events: events}, nil
+	}
+}
+
+// Head returns the global version of the last saved event
+func (m *Memory) Head() (core.Version, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return core.Version(len(m.eventsInOrder)), nil
+}
@@ -0,0 +1,289 @@
+package sql_test
+
+import (
+	"context"
+	sqldriver "database/sql"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/core/testsuite"
+	"github.com/hallgren/eventsourcing/eventstore/sql"
+	_ "github.com/lib/pq"
+)
+
+func TestSuiteCommitOrder(t *testing.T) {
+	f := func() (core.EventStore, func(), error) {
+		db, err := sqldriver.Open("sqlite3", "file::memory:?cache=shared")
+		if err != nil {
+			return nil, nil, err
+		}
+		db.SetMaxOpenConns(1)
+		es := sql.Open(db, sql.WithCommitOrder(), sql.WithOutbox())
+		err = es.Migrate()
+		if err != nil {
+			return nil, nil, err
+		}
+		return es, es.Close, nil
+	}
+	testsuite.Test(t, f)
+}
+
+// postgresEventstore returns an event store in the Postgres database in POSTGRES_DSN. The tables get a unique prefix
+// and are dropped when the test ends. The test is skipped if POSTGRES_DSN is not set.
+//
+// SQLite runs one write transaction at a time which makes the autoincrement seq follow the commit order, the gap
+// between the seq order and the commit order needs a database with concurrent writers.
+func postgresEventstore(t *testing.T, options ...sql.Option) (*sql.SQL, *sqldriver.DB) {
+	dsn := os.Getenv("POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("POSTGRES_DSN not set")
+	}
+	db, err := sqldriver.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prefix := fmt.Sprintf("test_%d_", time.Now().UnixNano())
+	es := sql.Open(db, append(options, sql.WithTablePrefix(prefix))...)
+	t.Cleanup(func() {
+		for _, table := range []string{"events", "outbox", "sequence_lock", "events_migrations"} {
+			_, err := db.Exec(`drop table if exists ` + prefix + table)
+			if err != nil {
+				t.Error(err)
+			}
+		}
+		db.Close()
+	})
+	err = es.Migrate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return es, db
+}
+
+// reader reads the events after its position in the same way as a projection
+type reader struct {
+	es       *sql.SQL
+	position core.Version
+	seen     []core.Version
+}
+
+func (r *reader) read(t *testing.T) {
+	t.Helper()
+	iterator, err := r.es.All(r.position+1, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer iterator.Close()
+	for iterator.Next() {
+		event, err := iterator.Value()
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.position = event.GlobalVersion
+		r.seen = append(r.seen, event.GlobalVersion)
+	}
+}
+
+// TestGapWithoutCommitOrder shows the problem that the commit order solves. The first transaction gets the lower seq
+// but commits after the second, and the reader has then read past the events of the first transaction.
+func TestGapWithoutCommitOrder(t *testing.T) {
+	es, db := postgresEventstore(t)
+	ctx := context.Background()
+	r := &reader{es: es}
+
+	first, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer first.Rollback()
+	err = es.SaveTx(ctx, first, outboxEvents("first"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer second.Rollback()
+	err = es.SaveTx(ctx, second, outboxEvents("second"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = second.Commit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.read(t)
+	err = first.Commit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.read(t)
+
+	if len(r.seen) != 2 {
+		t.Fatalf("expected the reader to miss the events of the first transaction, it read %v", r.seen)
+	}
+}
+
+// TestCommitOrder runs the same transactions as TestGapWithoutCommitOrder. The second transaction waits for the first
+// to commit and gets the seqs after it, and the reader reads all events.
+func TestCommitOrder(t *testing.T) {
+	es, db := postgresEventstore(t, sql.WithCommitOrder())
+	ctx := context.Background()
+	r := &reader{es: es}
+
+	first, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer first.Rollback()
+	err = es.SaveTx(ctx, first, outboxEvents("first"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer second.Rollback()
+	saved := make(chan error, 1)
+	go func() {
+		saved <- es.SaveTx(ctx, second, outboxEvents("second"))
+	}()
+	select {
+	case err = <-saved:
+		t.Fatalf("expected the second transaction to wait for the first, got %v", err)
+	case <-time.After(time.Millisecond * 200):
+	}
+
+	r.read(t)
+	err = first.Commit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = <-saved
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = second.Commit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.read(t)
+
+	expected := []core.Version{1, 2, 3, 4}
+	if fmt.Sprint(r.seen) != fmt.Sprint(expected) {
+		t.Fatalf("expected the reader to read %v got %v", expected, r.seen)
+	}
+}
+
+// Person and AgedOneYear are registered to make the projection handle the events saved by the tests
+type Person struct {
+	eventsourcing.AggregateRoot
+}
+
+type AgedOneYear struct{}
+
+func (p *Person) Transition(event eventsourcing.Event) {}
+
+func (p *Person) Register(r eventsourcing.RegisterFunc) {
+	r(&AgedOneYear{})
+}
+
+// TestProjectionConcurrentSaves runs a projection while events are saved from parallel go routines and expects it to
+// handle every global version once and in order. It runs against SQLite and against Postgres if POSTGRES_DSN is set.
+func TestProjectionConcurrentSaves(t *testing.T) {
+	tests := []struct {
+		name string
+		open func(t *testing.T) *sql.SQL
+	}{
+		{name: "sqlite", open: func(t *testing.T) *sql.SQL {
+			es := sql.Open(openDB(t, "projectionsaves"), sql.WithCommitOrder())
+			err := es.Migrate()
+			if err != nil {
+				t.Fatal(err)
+			}
+			return es
+		}},
+		{name: "postgres", open: func(t *testing.T) *sql.SQL {
+			es, _ := postgresEventstore(t, sql.WithCommitOrder())
+			return es
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			es := test.open(t)
+			register := eventsourcing.NewRegister()
+			register.Register(&Person{})
+			ph := eventsourcing.NewProjectionHandler(register, eventsourcing.EncoderJSON{})
+
+			var position core.Version
+			var seen []core.Version
+			p := ph.Projection(func() (core.Iterator, error) {
+				return es.All(position+1, 10)
+			}, func(e eventsourcing.Event) error {
+				position = core.Version(e.GlobalVersion())
+				seen = append(seen, position)
+				return nil
+			})
+
+			writers, saves := 5, 20
+			var wg sync.WaitGroup
+			errs := make(chan error, writers)
+			for w := 0; w < writers; w++ {
+				wg.Add(1)
+				go func(w int) {
+					defer wg.Done()
+					for i := 0; i < saves; i++ {
+						err := es.Save(personEvents(fmt.Sprintf("person-%d-%d", w, i), 2))
+						if err != nil {
+							errs <- err
+							return
+						}
+					}
+				}(w)
+			}
+			done := make(chan struct{})
+			go func() {
+				wg.Wait()
+				close(done)
+			}()
+
+			ctx := context.Background()
+			running := true
+			for running {
+				select {
+				case <-done:
+					running = false
+				default:
+				}
+				// the last run starts after the saves are done and reads the end of the event stream
+				result := p.RunToEnd(ctx)
+				if result.Error != nil {
+					t.Fatal(result.Error)
+				}
+			}
+			close(errs)
+			for err := range errs {
+				t.Fatal(err)
+			}
+
+			total := writers * saves * 2
+			if len(seen) != total {
+				t.Fatalf("expected the projection to handle %d events got %d", total, len(seen))
+			}
+			for i, globalVersion := range seen {
+				if globalVersion != core.Version(i+1) {
+					t.Fatalf("expected global version %d at index %d got %d", i+1, i, globalVersion)
+				}
+			}
+		})
+	}
+}
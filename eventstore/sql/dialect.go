@@ -0,0 +1,132 @@
+package sql
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Dialect renders the SQL that differs between databases, i.e. the DDL, the query placeholders and how the
+// global version of an inserted event is returned.
+type Dialect struct {
+	name        string
+	placeholder func(n int) string
+	createTable string
+	returning   bool // the insert returns the seq via RETURNING instead of LastInsertId
+	consecutive bool // the rows of a multi-row insert get consecutive seqs and LastInsertId returns the last one
+	indexSchema bool // the schema is set on the index name instead of the table name when creating an index
+}
+
+// SQLite dialect
+func SQLite() Dialect {
+	return Dialect{
+		name:        "sqlite",
+		placeholder: func(n int) string { return "?" },
+		createTable: `create table %s (seq INTEGER PRIMARY KEY AUTOINCREMENT, id VARCHAR NOT NULL, version INTEGER, reason VARCHAR, type VARCHAR, timestamp VARCHAR, data BLOB, metadata BLOB);`,
+		consecutive: true,
+		indexSchema: true,
+	}
+}
+
+// PostgreSQL dialect
+func PostgreSQL() Dialect {
+	return Dialect{
+		name:        "postgres",
+		placeholder: func(n int) string { return "$" + strconv.Itoa(n) },
+		createTable: `create table %s (seq SERIAL PRIMARY KEY, id VARCHAR NOT NULL, version INTEGER, reason VARCHAR, "type" VARCHAR, timestamp VARCHAR, data bytea, metadata bytea);`,
+		returning:   true,
+	}
+}
+
+// MySQL dialect
+func MySQL() Dialect {
+	return Dialect{
+		name:        "mysql",
+		placeholder: func(n int) string { return "?" },
+		createTable: `create table %s (seq INT UNIQUE PRIMARY KEY AUTO_INCREMENT, id VARCHAR(255) NOT NULL, version INTEGER, reason VARCHAR(255), type VARCHAR(255), timestamp VARCHAR(255), data BLOB, metadata BLOB);`,
+	}
+}
+
+// Name of the dialect
+func (d Dialect) Name() string {
+	return d.name
+}
+
+// Rebind replaces the ? placeholders in the query with the placeholders of the dialect
+func (d Dialect) Rebind(query string) string {
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString(d.placeholder(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// CreateTable returns the statement that creates the events table
+func (d Dialect) CreateTable(table string) string {
+	return fmt.Sprintf(d.createTable, table)
+}
+
+// CreateIndex returns the statement that creates an index on the columns of the table in the schema. The schema is
+// optional.
+func (d Dialect) CreateIndex(unique bool, schema, index, table, columns string) string {
+	statement := "create index"
+	if unique {
+		statement = "create unique index"
+	}
+	if schema != "" {
+		if d.indexSchema {
+			index = schema + "." + index
+		} else {
+			table = schema + "." + table
+		}
+	}
+	return fmt.Sprintf("%s %s on %s (%s);", statement, index, table, columns)
+}
+
+// InsertEvents returns the statement that inserts the number of events in the table in one multi-row insert. If the
+// dialect returns the seq from the insert the statement is executed as a query returning the seq and version of
+// each row.
+func (d Dialect) InsertEvents(table string, rows int) string {
+	insert := `Insert into ` + table + ` (id, version, reason, type, timestamp, timestamp_nano, data, metadata) values ` + values(8, rows)
+	if d.returning {
+		insert += ` RETURNING seq, version`
+	}
+	return d.Rebind(insert)
+}
+
+// InsertEventsWithSeq returns the statement that inserts the number of events with given seqs in the table in one
+// multi-row insert
+func (d Dialect) InsertEventsWithSeq(table string, rows int) string {
+	return d.Rebind(`Insert into ` + table + ` (seq, id, version, reason, type, timestamp, timestamp_nano, data, metadata) values ` + values(9, rows))
+}
+
+// multiRowSeq returns true if the seq of each row in a multi-row insert is known to the dialect
+func (d Dialect) multiRowSeq() bool {
+	return d.returning || d.consecutive
+}
+
+// values returns the placeholders of the rows in a multi-row insert, e.g. (?, ?), (?, ?)
+func values(columns, rows int) string {
+	row := "(" + strings.TrimSuffix(strings.Repeat("?, ", columns), ", ") + ")"
+	return strings.TrimSuffix(strings.Repeat(row+", ", rows), ", ")
+}
+
+// dialectFromDriver returns the dialect based on the driver type, SQLite if the driver is unknown. It's used when
+// no dialect is set, to keep the stores opened before the WithDialect option working.
+func dialectFromDriver(driver driver.Driver) Dialect {
+	switch reflect.TypeOf(driver).String() {
+	case "*pq.Driver", "*stdlib.Driver":
+		return PostgreSQL()
+	case "*mysql.MySQLDriver":
+		return MySQL()
+	}
+	return SQLite()
+}
@@ -0,0 +1,139 @@
+package sql_test
+
+import (
+	sqldriver "database/sql"
+	"strings"
+	"testing"
+
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/core/testsuite"
+	"github.com/hallgren/eventsourcing/eventstore/sql"
+)
+
+func TestRebind(t *testing.T) {
+	query := `Select seq from events where id=? and type=? LIMIT ?`
+	tests := []struct {
+		dialect  sql.Dialect
+		expected string
+	}{
+		{sql.SQLite(), `Select seq from events where id=? and type=? LIMIT ?`},
+		{sql.MySQL(), `Select seq from events where id=? and type=? LIMIT ?`},
+		{sql.PostgreSQL(), `Select seq from events where id=$1 and type=$2 LIMIT $3`},
+	}
+	for _, test := range tests {
+		t.Run(test.dialect.Name(), func(t *testing.T) {
+			if got := test.dialect.Rebind(query); got != test.expected {
+				t.Fatalf("expected %s got %s", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestInsertEventsOneRow(t *testing.T) {
+	tests := []struct {
+		dialect  sql.Dialect
+		expected string
+	}{
+		{sql.SQLite(), `Insert into events (id, version, reason, type, timestamp, timestamp_nano, data, metadata) values (?, ?, ?, ?, ?, ?, ?, ?)`},
+		{sql.MySQL(), `Insert into events (id, version, reason, type, timestamp, timestamp_nano, data, metadata) values (?, ?, ?, ?, ?, ?, ?, ?)`},
+		{sql.PostgreSQL(), `Insert into events (id, version, reason, type, timestamp, timestamp_nano, data, metadata) values ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING seq, version`},
+	}
+	for _, test := range tests {
+		t.Run(test.dialect.Name(), func(t *testing.T) {
+			if got := test.dialect.InsertEvents("events", 1); got != test.expected {
+				t.Fatalf("expected %s got %s", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestCreateTable(t *testing.T) {
+	tests := []struct {
+		dialect sql.Dialect
+		seq     string
+	}{
+		{sql.SQLite(), "seq INTEGER PRIMARY KEY AUTOINCREMENT"},
+		{sql.MySQL(), "seq INT UNIQUE PRIMARY KEY AUTO_INCREMENT"},
+		{sql.PostgreSQL(), "seq SERIAL PRIMARY KEY"},
+	}
+	for _, test := range tests {
+		t.Run(test.dialect.Name(), func(t *testing.T) {
+			if !strings.Contains(test.dialect.CreateTable("events"), test.seq) {
+				t.Fatalf("expected %s in %s", test.seq, test.dialect.CreateTable("events"))
+			}
+		})
+	}
+}
+
+func TestCreateIndex(t *testing.T) {
+	tests := []struct {
+		dialect  sql.Dialect
+		expected string
+	}{
+		{sql.SQLite(), `create unique index ctx.id_type on events (id, type);`},
+		{sql.MySQL(), `create unique index id_type on ctx.events (id, type);`},
+		{sql.PostgreSQL(), `create unique index id_type on ctx.events (id, type);`},
+	}
+	for _, test := range tests {
+		t.Run(test.dialect.Name(), func(t *testing.T) {
+			if got := test.dialect.CreateIndex(true, "ctx", "id_type", "events", "id, type"); got != test.expected {
+				t.Fatalf("expected %s got %s", test.expected, got)
+			}
+		})
+	}
+	if got := sql.SQLite().CreateIndex(false, "", "id_type", "events", "id, type"); got != `create index id_type on events (id, type);` {
+		t.Fatalf("wrong index without schema %s", got)
+	}
+}
+
+func TestSuiteSQLiteDialect(t *testing.T) {
+	f := func() (core.EventStore, func(), error) {
+		db, err := sqldriver.Open("sqlite3", "file::memory:?cache=shared")
+		if err != nil {
+			return nil, nil, err
+		}
+		db.SetMaxOpenConns(1)
+		es := sql.Open(db, sql.WithDialect(sql.SQLite()))
+		err = es.Migrate()
+		if err != nil {
+			return nil, nil, err
+		}
+		return es, es.Close, nil
+	}
+	testsuite.Test(t, f)
+}
+
+func TestInsertEventsWithSeqOneRow(t *testing.T) {
+	tests := []struct {
+		dialect  sql.Dialect
+		expected string
+	}{
+		{sql.SQLite(), `Insert into events (seq, id, version, reason, type, timestamp, timestamp_nano, data, metadata) values (?, ?, ?, ?, ?, ?, ?, ?, ?)`},
+		{sql.PostgreSQL(), `Insert into events (seq, id, version, reason, type, timestamp, timestamp_nano, data, metadata) values ($1, $2, $3, $4, $5, $6, $7, $8, $9)`},
+	}
+	for _, test := range tests {
+		t.Run(test.dialect.Name(), func(t *testing.T) {
+			if got := test.dialect.InsertEventsWithSeq("events", 1); got != test.expected {
+				t.Fatalf("expected %s got %s", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestInsertEvents(t *testing.T) {
+	tests := []struct {
+		dialect  sql.Dialect
+		expected string
+	}{
+		{sql.SQLite(), `Insert into events (id, version, reason, type, timestamp, timestamp_nano, data, metadata) values (?, ?, ?, ?, ?, ?, ?, ?), (?, ?, ?, ?, ?, ?, ?, ?)`},
+		{sql.PostgreSQL(), `Insert into events (id, version, reason, type, timestamp, timestamp_nano, data, metadata) values ($1, $2, $3, $4, $5, $6, $7, $8), ($9, $10, $11, $12, $13, $14, $15, $16) RETURNING seq, version`},
+		{sql.MySQL(), `Insert into events (id, version, reason, type, timestamp, timestamp_nano, data, metadata) values (?, ?, ?, ?, ?, ?, ?, ?), (?, ?, ?, ?, ?, ?, ?, ?)`},
+	}
+	for _, test := range tests {
+		t.Run(test.dialect.Name(), func(t *testing.T) {
+			if got := test.dialect.InsertEvents("events", 2); got != test.expected {
+				t.Fatalf("expected %s got %s", test.expected, got)
+			}
+		})
+	}
+}
@@ -3,7 +3,9 @@ module github.com/hallgren/eventsourcing/eventstore/sql
 go 1.13
 
 require (
+	github.com/hallgren/eventsourcing v0.6.0
 	github.com/hallgren/eventsourcing/core v0.4.0
+	github.com/lib/pq v1.10.9
 	github.com/mattn/go-sqlite3 v1.14.22
 )
 
@@ -0,0 +1,234 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/hallgren/eventsourcing/core"
+)
+
+// statements binds the statements prepared on the event store to the transaction. The statements are prepared once on
+// the database, i.e. the version select and the insert of each row count, and reused by the following saves. A query
+// without a prepared statement is executed directly in the transaction.
+type statements struct {
+	ctx   context.Context
+	tx    *sql.Tx
+	store *SQL
+	stmts map[string]*sql.Stmt
+}
+
+func newStatements(ctx context.Context, tx *sql.Tx, store *SQL) *statements {
+	return &statements{ctx: ctx, tx: tx, store: store, stmts: make(map[string]*sql.Stmt)}
+}
+
+// prepare returns the prepared statement of the query bound to the transaction or nil if the query is not prepared
+func (s *statements) prepare(query string) *sql.Stmt {
+	if stmt, ok := s.stmts[query]; ok {
+		return stmt
+	}
+	stmt := s.store.prepared(query)
+	if stmt == nil {
+		return nil
+	}
+	stmt = s.tx.StmtContext(s.ctx, stmt)
+	s.stmts[query] = stmt
+	return stmt
+}
+
+// exec executes the query in the transaction
+func (s *statements) exec(query string, args ...interface{}) (sql.Result, error) {
+	if stmt := s.prepare(query); stmt != nil {
+		return stmt.ExecContext(s.ctx, args...)
+	}
+	return s.tx.ExecContext(s.ctx, query, args...)
+}
+
+// query executes the query that returns rows in the transaction
+func (s *statements) query(query string, args ...interface{}) (*sql.Rows, error) {
+	if stmt := s.prepare(query); stmt != nil {
+		return stmt.QueryContext(s.ctx, args...)
+	}
+	return s.tx.QueryContext(s.ctx, query, args...)
+}
+
+// queryRow executes the query that returns one row in the transaction
+func (s *statements) queryRow(query string, args ...interface{}) *sql.Row {
+	if stmt := s.prepare(query); stmt != nil {
+		return stmt.QueryRowContext(s.ctx, args...)
+	}
+	return s.tx.QueryRowContext(s.ctx, query, args...)
+}
+
+// close closes the statements bound to the transaction, the statements prepared on the event store are kept
+func (s *statements) close() {
+	for _, stmt := range s.stmts {
+		stmt.Close()
+	}
+}
+
+// prepared returns the statement of the query prepared on the database or nil if it's not prepared
+func (s *SQL) prepared(query string) *sql.Stmt {
+	s.stmtsLock.Lock()
+	defer s.stmtsLock.Unlock()
+	return s.stmts[query]
+}
+
+// prepareSave prepares the statements of a save of the number of events that are not already prepared. It's called
+// before the transaction is started as the prepare takes a connection from the pool.
+func (s *SQL) prepareSave(ctx context.Context, count int) error {
+	s.stmtsLock.Lock()
+	defer s.stmtsLock.Unlock()
+	for _, query := range s.saveQueries(count) {
+		if _, ok := s.stmts[query]; ok {
+			continue
+		}
+		stmt, err := s.db.PrepareContext(ctx, query)
+		if err != nil {
+			return err
+		}
+		s.stmts[query] = stmt
+	}
+	return nil
+}
+
+// saveQueries returns the queries executed by a save of the number of events
+func (s *SQL) saveQueries(count int) []string {
+	queries := []string{s.versionQuery()}
+	if s.commitOrder {
+		queries = append(queries, s.lockQuery(), s.lastSeqQuery())
+	}
+	batchSize := s.insertBatchSize()
+	rows := []int{count % batchSize}
+	if count >= batchSize {
+		rows = append(rows, batchSize)
+	}
+	for _, r := range rows {
+		if r == 0 {
+			continue
+		}
+		queries = append(queries, s.insertQuery(r))
+		if s.outbox {
+			queries = append(queries, s.outboxQuery(r))
+		}
+	}
+	return queries
+}
+
+// insertBatchSize returns the max number of events in one insert, the events are inserted with multi-row inserts
+// when the seq of each inserted row is known
+func (s *SQL) insertBatchSize() int {
+	if !s.commitOrder && !s.dialect.multiRowSeq() {
+		return 1
+	}
+	return s.batchSize
+}
+
+// versionQuery returns the query of the version of the last event of the aggregate
+func (s *SQL) versionQuery() string {
+	return s.dialect.Rebind(`Select version from ` + s.table("events") + ` where id=? and type=? order by version desc limit 1`)
+}
+
+// lockQuery returns the query that takes the sequence lock
+func (s *SQL) lockQuery() string {
+	return s.dialect.Rebind(`Update ` + s.table("sequence_lock") + ` set id = 1 where id = 1`)
+}
+
+// lastSeqQuery returns the query of the seq of the last saved event
+func (s *SQL) lastSeqQuery() string {
+	return `Select coalesce(max(seq), 0) from ` + s.table("events")
+}
+
+// insertQuery returns the insert of the number of events
+func (s *SQL) insertQuery(rows int) string {
+	if s.commitOrder {
+		return s.dialect.InsertEventsWithSeq(s.table("events"), rows)
+	}
+	return s.dialect.InsertEvents(s.table("events"), rows)
+}
+
+// outboxQuery returns the insert of the number of events in the outbox
+func (s *SQL) outboxQuery(rows int) string {
+	return s.dialect.Rebind(`Insert into ` + s.table("outbox") + ` (seq) values ` + values(1, rows))
+}
+
+// insert inserts the events in one statement and sets their global version
+func (s *SQL) insert(stmts *statements, events []core.Event) error {
+	insert := s.insertQuery(len(events))
+	args := make([]interface{}, 0, len(events)*8)
+	for _, event := range events {
+		args = append(args, event.AggregateID, event.Version, event.Reason, event.AggregateType, event.Timestamp.Format(time.RFC3339), timestampNano(event.Timestamp), event.Data, event.Metadata)
+	}
+
+	if s.dialect.returning {
+		rows, err := stmts.query(insert, args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		// the order of the returned rows is not guaranteed, the seq is matched to the event via the version
+		seqs := make(map[core.Version]core.Version, len(events))
+		for rows.Next() {
+			var seq, version core.Version
+			err = rows.Scan(&seq, &version)
+			if err != nil {
+				return err
+			}
+			seqs[version] = seq
+		}
+		if err = rows.Err(); err != nil {
+			return err
+		}
+		for i := range events {
+			seq, ok := seqs[events[i].Version]
+			if !ok {
+				return fmt.Errorf("no seq returned for version %d", events[i].Version)
+			}
+			events[i].GlobalVersion = seq
+		}
+		return nil
+	}
+
+	res, err := stmts.exec(insert, args...)
+	if err != nil {
+		return err
+	}
+	last, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	// the rows got consecutive seqs ending with the last inserted id
+	for i := range events {
+		events[i].GlobalVersion = core.Version(last - int64(len(events)-1-i))
+	}
+	return nil
+}
+
+// timestampNano returns the timestamp in nanoseconds since the Unix epoch, NULL if the timestamp is outside the
+// range of an int64
+func timestampNano(t time.Time) sql.NullInt64 {
+	nano := t.UnixNano()
+	return sql.NullInt64{Int64: nano, Valid: time.Unix(0, nano).Equal(t)}
+}
+
+// insertWithSeq inserts the events in one statement with the seqs after the seq
+func (s *SQL) insertWithSeq(stmts *statements, seq int64, events []core.Event) error {
+	args := make([]interface{}, 0, len(events)*9)
+	for i, event := range events {
+		events[i].GlobalVersion = core.Version(seq + int64(i) + 1)
+		args = append(args, events[i].GlobalVersion, event.AggregateID, event.Version, event.Reason, event.AggregateType, event.Timestamp.Format(time.RFC3339), timestampNano(event.Timestamp), event.Data, event.Metadata)
+	}
+	_, err := stmts.exec(s.insertQuery(len(events)), args...)
+	return err
+}
+
+// insertOutbox inserts a reference to the events in the outbox in one statement
+func (s *SQL) insertOutbox(stmts *statements, events []core.Event) error {
+	args := make([]interface{}, len(events))
+	for i, event := range events {
+		args[i] = event.GlobalVersion
+	}
+	_, err := stmts.exec(s.outboxQuery(len(events)), args...)
+	return err
+}
@@ -0,0 +1,156 @@
+package sql_test
+
+import (
+	"context"
+	sqldriver "database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/eventstore/sql"
+)
+
+func TestSaveBatchGlobalVersions(t *testing.T) {
+	tests := []struct {
+		name    string
+		options []sql.Option
+	}{
+		{name: "default"},
+		{name: "row", options: []sql.Option{sql.WithBatchSize(1)}},
+		{name: "batch", options: []sql.Option{sql.WithBatchSize(3), sql.WithOutbox()}},
+		{name: "commitorder", options: []sql.Option{sql.WithBatchSize(3), sql.WithCommitOrder(), sql.WithOutbox()}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			es := sql.Open(openDB(t, "batch"+test.name), test.options...)
+			err := es.Migrate()
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = es.Save(personEvents("first", 2))
+			if err != nil {
+				t.Fatal(err)
+			}
+			events := personEvents("second", 10)
+			err = es.Save(events)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			iter, err := es.All(3, 100)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer iter.Close()
+			i := 0
+			for iter.Next() {
+				stored, err := iter.Value()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if events[i].GlobalVersion != stored.GlobalVersion || events[i].Version != stored.Version {
+					t.Fatalf("expected version %d with global version %d got version %d with global version %d", stored.Version, stored.GlobalVersion, events[i].Version, events[i].GlobalVersion)
+				}
+				i++
+			}
+			if i != len(events) {
+				t.Fatalf("expected %d stored events got %d", len(events), i)
+			}
+		})
+	}
+}
+
+// BenchmarkSave compares the write path before the multi-row inserts, a statement per event executed without
+// prepare, with saving the events via the event store in statements of one row and in multi-row inserts. Save uses
+// the statements prepared once on the event store, SaveTx without an earlier Save executes the statements unprepared.
+func BenchmarkSave(b *testing.B) {
+	cases := []struct {
+		name      string
+		batchSize int
+		saveTx    bool
+	}{
+		{name: "baseline"},
+		{name: "batch-1", batchSize: 1},
+		{name: "batch-100", batchSize: 100},
+		{name: "savetx-batch-100", batchSize: 100, saveTx: true},
+	}
+	for _, count := range []int{1, 10, 100} {
+		for _, c := range cases {
+			b.Run(fmt.Sprintf("events-%d/%s", count, c.name), func(b *testing.B) {
+				db, err := sqldriver.Open("sqlite3", fmt.Sprintf("file:bench-%d-%s?mode=memory&cache=shared", count, c.name))
+				if err != nil {
+					b.Fatal(err)
+				}
+				db.SetMaxOpenConns(1)
+				es := sql.Open(db, sql.WithBatchSize(c.batchSize))
+				defer es.Close()
+				err = es.Migrate()
+				if err != nil {
+					b.Fatal(err)
+				}
+				save := es.Save
+				if c.batchSize == 0 {
+					save = func(events []core.Event) error {
+						return saveBaseline(db, events)
+					}
+				}
+				if c.saveTx {
+					save = func(events []core.Event) error {
+						tx, err := db.Begin()
+						if err != nil {
+							return err
+						}
+						defer tx.Rollback()
+						err = es.SaveTx(context.Background(), tx, events)
+						if err != nil {
+							return err
+						}
+						return tx.Commit()
+					}
+				}
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					err = save(personEvents(fmt.Sprintf("person-%d", i), count))
+					if err != nil {
+						b.Fatal(err)
+					}
+				}
+				b.ReportMetric(float64(b.N*count)/b.Elapsed().Seconds(), "events/s")
+			})
+		}
+	}
+}
+
+// saveBaseline saves the events the way the event store did before the multi-row inserts, with the version check
+// and a statement executed per event in the transaction
+func saveBaseline(db *sqldriver.DB, events []core.Event) error {
+	ctx := context.Background()
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var version int
+	err = tx.QueryRowContext(ctx, `Select version from events where id=? and type=? order by version desc limit 1`, events[0].AggregateID, events[0].AggregateType).Scan(&version)
+	if err != nil && err != sqldriver.ErrNoRows {
+		return err
+	}
+	if core.Version(version)+1 != events[0].Version {
+		return core.ErrConcurrency
+	}
+	for i, event := range events {
+		res, err := tx.ExecContext(ctx, `Insert into events (id, version, reason, type, timestamp, timestamp_nano, data, metadata) values (?, ?, ?, ?, ?, ?, ?, ?)`,
+			event.AggregateID, event.Version, event.Reason, event.AggregateType, event.Timestamp.Format(time.RFC3339), event.Timestamp.UnixNano(), event.Data, event.Metadata)
+		if err != nil {
+			return err
+		}
+		seq, err := res.LastInsertId()
+		if err != nil {
+			return err
+		}
+		events[i].GlobalVersion = core.Version(seq)
+	}
+	return tx.Commit()
+}
@@ -1,6 +1,7 @@
 package sql
 
 import (
+	"context"
 	"database/sql"
 	"time"
 
@@ -18,12 +19,107 @@ func (i *iterator) Next() bool {
 
 // Value return the an event
 func (i *iterator) Value() (core.Event, error) {
+	return scanEvent(i.rows)
+}
+
+// Close closes the iterator
+func (i *iterator) Close() {
+	i.rows.Close()
+}
+
+// pageIterator reads the events a page at a time with a keyset query. The page is read into memory which
+// releases the connection before the events are returned. An error when fetching a page is returned from Value.
+type pageIterator struct {
+	ctx      context.Context
+	db       *sql.DB
+	query    string        // query is called with the args followed by the key to read after and the page size
+	args     []interface{} // args are the query args before the key
+	key      func(e core.Event) core.Version
+	after    core.Version
+	pageSize uint64
+	left     uint64 // left is the number of events left to read
+	page     []core.Event
+	index    int
+	done     bool
+	err      error
+}
+
+// Next return true if there are more data
+func (i *pageIterator) Next() bool {
+	if i.err != nil {
+		// the error is returned once from Value
+		return false
+	}
+	i.index++
+	if i.index < len(i.page) {
+		return true
+	}
+	if i.done {
+		return false
+	}
+	i.err = i.fetch()
+	return i.err != nil || len(i.page) > 0
+}
+
+// fetch reads the next page
+func (i *pageIterator) fetch() error {
+	i.page = i.page[:0]
+	i.index = 0
+	limit := i.pageSize
+	if i.left < limit {
+		limit = i.left
+	}
+	if limit == 0 {
+		i.done = true
+		return nil
+	}
+	rows, err := i.db.QueryContext(i.ctx, i.query, append(i.args, i.after, limit)...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		event, err := scanEvent(rows)
+		if err != nil {
+			return err
+		}
+		i.page = append(i.page, event)
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+	// a page that is not full is the last one
+	i.done = uint64(len(i.page)) < limit
+	i.left -= uint64(len(i.page))
+	if len(i.page) > 0 {
+		i.after = i.key(i.page[len(i.page)-1])
+	}
+	return nil
+}
+
+// Value return the an event
+func (i *pageIterator) Value() (core.Event, error) {
+	if i.err != nil {
+		return core.Event{}, i.err
+	}
+	return i.page[i.index], nil
+}
+
+// Close closes the iterator
+func (i *pageIterator) Close() {
+	i.page = nil
+	i.done = true
+}
+
+// scanEvent scans the event from the current row
+func scanEvent(rows *sql.Rows) (core.Event, error) {
 	var globalVersion core.Version
 	var version core.Version
 	var id, reason, typ, timestamp string
+	var timestampNano sql.NullInt64
 	var data, metadata []byte
 
-	if err := i.rows.Scan(&globalVersion, &id, &version, &reason, &typ, &timestamp, &data, &metadata); err != nil {
+	if err := rows.Scan(&globalVersion, &id, &version, &reason, &typ, &timestamp, &timestampNano, &data, &metadata); err != nil {
 		return core.Event{}, err
 	}
 
@@ -31,6 +127,10 @@ func (i *iterator) Value() (core.Event, error) {
 	if err != nil {
 		return core.Event{}, err
 	}
+	// events saved before the timestamp_nano column was added only have the timestamp in seconds
+	if timestampNano.Valid {
+		t = time.Unix(0, timestampNano.Int64).In(t.Location())
+	}
 
 	event := core.Event{
 		AggregateID:   id,
@@ -44,8 +144,3 @@ func (i *iterator) Value() (core.Event, error) {
 	}
 	return event, nil
 }
-
-// Close closes the iterator
-func (i *iterator) Close() {
-	i.rows.Close()
-}
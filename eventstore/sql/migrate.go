@@ -3,69 +3,157 @@ package sql
 import (
 	"context"
 	"database/sql"
-	"database/sql/driver"
+	"fmt"
 	"log"
-	"reflect"
+	"time"
 )
 
-const createTable = `create table events (seq INTEGER PRIMARY KEY AUTOINCREMENT, id VARCHAR NOT NULL, version INTEGER, reason VARCHAR, type VARCHAR, timestamp VARCHAR, data BLOB, metadata BLOB);`
-const createTableMySql = `create table events (seq INT UNIQUE PRIMARY KEY AUTO_INCREMENT, id VARCHAR(255) NOT NULL, version INTEGER, reason VARCHAR(255), type VARCHAR(255), timestamp VARCHAR(255), data BLOB, metadata BLOB);`
-const createTablePSQL = `create table events (seq SERIAL PRIMARY KEY, id VARCHAR NOT NULL, version INTEGER, reason VARCHAR, "type" VARCHAR, timestamp VARCHAR, data bytea, metadata bytea);`
+const createOutboxTable = `create table %s (seq INTEGER PRIMARY KEY);`
 
-func getCreateTableStmt(driver driver.Driver) string {
-	driverType := reflect.TypeOf(driver).String()
-	switch driverType {
-	case "*pq.Driver":
-		log.Print("Use migrating statement for PostgresSQL")
-		return createTablePSQL
-	case "*mysql.MySQLDriver":
-		log.Print("Use migrating statement for MySQL")
-		return createTableMySql
-	case "*sqlite3.SQLiteDriver":
-		log.Print("Use migrating statement for SQLite")
-		return createTable
+// migrationsTable records the applied migrations
+const migrationsTable = "events_migrations"
+
+// Migration is a schema change that is applied once to the database
+type Migration struct {
+	Version     int
+	Description string
+	Statements  []string
+}
+
+// Migrations returns the migrations in the order they are applied. New migrations are added last with the next version.
+func (s *SQL) Migrations() []Migration {
+	return []Migration{
+		{
+			Version:     1,
+			Description: "create events table",
+			Statements: []string{
+				s.dialect.CreateTable(s.table("events")),
+				s.createIndex(true, "id_type_version", "events", "id, type, version"),
+				s.createIndex(false, "id_type", "events", "id, type"),
+			},
+		},
+		{
+			Version:     2,
+			Description: "create outbox table",
+			Statements:  []string{fmt.Sprintf(createOutboxTable, s.table("outbox"))},
+		},
+		{
+			Version:     3,
+			Description: "create sequence lock table",
+			Statements: []string{
+				fmt.Sprintf(`create table %s (id INTEGER PRIMARY KEY);`, s.table("sequence_lock")),
+				fmt.Sprintf(`insert into %s (id) values (1);`, s.table("sequence_lock")),
+			},
+		},
+		{
+			Version:     4,
+			Description: "add timestamp in nanoseconds to the events",
+			// the timestamp column only holds seconds, it's kept for the events saved before the migration
+			Statements: []string{fmt.Sprintf(`alter table %s add column timestamp_nano BIGINT;`, s.table("events"))},
+		},
 	}
-	log.Print("Driver could not be identified, use default statement (SQLite)")
-	return createTable
 }
 
-// Migrate the database
+// Migrate applies the migrations that are not already applied to the database
 func (s *SQL) Migrate() error {
-	sqlStmt := []string{
-		getCreateTableStmt(s.db.Driver()),
-		`create unique index id_type_version on events (id, type, version);`,
-		`create index id_type on events (id, type);`,
+	_, err := s.migrate(false)
+	return err
+}
+
+// MigrateDryRun returns the migrations that Migrate would apply without applying them
+func (s *SQL) MigrateDryRun() ([]Migration, error) {
+	return s.migrate(true)
+}
+
+func (s *SQL) migrate(dryRun bool) ([]Migration, error) {
+	applied, recorded, err := s.appliedMigrations()
+	if err != nil {
+		return nil, err
+	}
+	pending := make([]Migration, 0)
+	for _, m := range s.Migrations() {
+		if !applied[m.Version] {
+			pending = append(pending, m)
+		}
+	}
+	if dryRun {
+		return pending, nil
+	}
+
+	_, err = s.db.Exec(`create table if not exists ` + s.table(migrationsTable) + ` (version INTEGER PRIMARY KEY, description VARCHAR(255), applied_at VARCHAR(255));`)
+	if err != nil {
+		return nil, err
 	}
-	return s.migrate(sqlStmt)
+	// record the migrations that was applied before the migrations were recorded
+	for _, m := range s.Migrations() {
+		if applied[m.Version] && !recorded[m.Version] {
+			err = s.applyMigration(Migration{Version: m.Version, Description: m.Description})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, m := range pending {
+		err = s.applyMigration(m)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return pending, nil
 }
 
-func (s *SQL) migrate(stm []string) error {
+// appliedMigrations returns the applied and the recorded migrations. A database migrated before the migrations
+// were recorded has the events table but no recorded migrations, the first migration is then applied but not recorded.
+func (s *SQL) appliedMigrations() (map[int]bool, map[int]bool, error) {
+	applied := make(map[int]bool)
+	recorded := make(map[int]bool)
+	rows, err := s.db.Query(`Select version from ` + s.table(migrationsTable))
+	if err != nil {
+		// the migrations table does not exist
+		rows, err := s.db.Query(`Select count(*) from ` + s.table("events"))
+		if err == nil {
+			applied[1] = true
+			return applied, recorded, rows.Close()
+		}
+		return applied, recorded, nil
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var version int
+		err = rows.Scan(&version)
+		if err != nil {
+			return nil, nil, err
+		}
+		applied[version] = true
+		recorded[version] = true
+	}
+	return applied, recorded, rows.Err()
+}
+
+// applyMigration executes the migration statements and records it in one transaction
+func (s *SQL) applyMigration(m Migration) error {
 	tx, err := s.db.BeginTx(context.Background(), nil)
 	if err != nil {
 		return err
 	}
 	defer func(tx *sql.Tx) {
 		err := tx.Rollback()
-		if err != nil {
+		if err != nil && err != sql.ErrTxDone {
 			log.Printf("could not rollback transaction %v", err)
 		}
 	}(tx)
 
-	// check if the migration is already done
-	rows, err := tx.Query(`Select count(*) from events`)
-	if err == nil {
-		err := rows.Close()
+	for _, statement := range m.Statements {
+		_, err := tx.Exec(statement)
 		if err != nil {
 			return err
 		}
-		return nil
 	}
-
-	for _, b := range stm {
-		_, err := tx.Exec(b)
-		if err != nil {
-			return err
-		}
+	insert := s.dialect.Rebind(`Insert into ` + s.table(migrationsTable) + ` (version, description, applied_at) values (?, ?, ?)`)
+	_, err = tx.Exec(insert, m.Version, m.Description, time.Now().UTC().Format(time.RFC3339))
+	if err != nil {
+		return err
 	}
 	return tx.Commit()
 }
@@ -0,0 +1,175 @@
+package sql_test
+
+import (
+	"context"
+	sqldriver "database/sql"
+	"testing"
+	"time"
+
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/eventstore/sql"
+)
+
+func openDB(t *testing.T, name string) *sqldriver.DB {
+	db, err := sqldriver.Open("sqlite3", "file:"+name+"?mode=memory&cache=shared")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func versions(migrations []sql.Migration) []int {
+	v := make([]int, 0, len(migrations))
+	for _, m := range migrations {
+		v = append(v, m.Version)
+	}
+	return v
+}
+
+func TestMigrateDryRun(t *testing.T) {
+	es := sql.Open(openDB(t, "dryrun"))
+
+	pending, err := es.MigrateDryRun()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != len(es.Migrations()) {
+		t.Fatalf("expected all migrations to be pending got %v", versions(pending))
+	}
+	// the dry run should not apply the migrations
+	pending, err = es.MigrateDryRun()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != len(es.Migrations()) {
+		t.Fatalf("expected all migrations to be pending got %v", versions(pending))
+	}
+
+	err = es.Migrate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pending, err = es.MigrateDryRun()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 0 {
+		t.Fatalf("expected no pending migrations got %v", versions(pending))
+	}
+}
+
+func TestMigrateExistingDatabase(t *testing.T) {
+	db := openDB(t, "existing")
+	// the events table created before the migrations were recorded
+	for _, statement := range sql.Open(db).Migrations()[0].Statements {
+		_, err := db.Exec(statement)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err := db.Exec(`Insert into events (id, version, reason, type, timestamp, data, metadata) values ('1', 1, 'Born', 'Person', '2024-01-01T12:00:00Z', '{}', '{}')`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	es := sql.Open(db)
+	pending, err := es.MigrateDryRun()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// all migrations but the first are pending
+	if len(pending) != len(es.Migrations())-1 || pending[0].Version != 2 {
+		t.Fatalf("expected the migrations after 1 to be pending got %v", versions(pending))
+	}
+	err = es.Migrate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the events are kept
+	head, err := es.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if head != 1 {
+		t.Fatalf("expected head 1 got %d", head)
+	}
+	var recorded int
+	err = db.QueryRow(`Select count(*) from events_migrations`).Scan(&recorded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recorded != len(es.Migrations()) {
+		t.Fatalf("expected %d recorded migrations got %d", len(es.Migrations()), recorded)
+	}
+}
+
+func TestMigrateTimestampNano(t *testing.T) {
+	db := openDB(t, "timestampnano")
+	// a database migrated to version 3, before the timestamp in nanoseconds was added
+	es := sql.Open(db)
+	_, err := db.Exec(`create table events_migrations (version INTEGER PRIMARY KEY, description VARCHAR(255), applied_at VARCHAR(255));`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range es.Migrations()[:3] {
+		for _, statement := range m.Statements {
+			_, err = db.Exec(statement)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		_, err = db.Exec(`Insert into events_migrations (version, description, applied_at) values (?, ?, ?)`, m.Version, m.Description, "2024-01-01T12:00:00Z")
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err = db.Exec(`Insert into events (id, version, reason, type, timestamp, data, metadata) values ('123', 1, 'Born', 'Person', '2024-01-01T12:00:00+02:00', '{}', '{}')`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pending, err := es.MigrateDryRun()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 1 || pending[0].Version != 4 {
+		t.Fatalf("expected migration 4 to be pending got %v", versions(pending))
+	}
+	err = es.Migrate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	timestamp := time.Date(2024, 1, 2, 12, 0, 0, 123456789, time.UTC)
+	err = es.Save([]core.Event{{AggregateID: "123", Version: 2, AggregateType: "Person", Reason: "AgedOneYear", Timestamp: timestamp, Data: []byte("{}")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	iter, err := es.Get(context.Background(), "123", "Person", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer iter.Close()
+	expected := []time.Time{
+		// the event saved before the migration keeps the timestamp in seconds
+		time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC),
+		timestamp,
+	}
+	i := 0
+	for ; iter.Next(); i++ {
+		event, err := iter.Value()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !event.Timestamp.Equal(expected[i]) {
+			t.Fatalf("expected timestamp %v got %v", expected[i], event.Timestamp)
+		}
+	}
+	if i != len(expected) {
+		t.Fatalf("expected %d events got %d", len(expected), i)
+	}
+}
@@ -0,0 +1,107 @@
+package sql
+
+import (
+	"context"
+	"time"
+
+	"github.com/hallgren/eventsourcing/core"
+)
+
+// Publisher hands events from the outbox over to an external system
+type Publisher interface {
+	Publish(ctx context.Context, event core.Event) error
+}
+
+// Relay reads undelivered events from the outbox, publish them and removes them from the outbox.
+//
+// An event is removed from the outbox after it's successfully published. If the process crash
+// in between the event will be published again, i.e. at-least-once delivery.
+type Relay struct {
+	store     *SQL
+	publisher Publisher
+	BatchSize uint64        // BatchSize is the max number of events fetched from the outbox in one run
+	Pace      time.Duration // Pace is the time to wait when the outbox is empty
+}
+
+// Relay creates a relay that publish events from the outbox via the publisher
+func (s *SQL) Relay(publisher Publisher) *Relay {
+	return &Relay{
+		store:     s,
+		publisher: publisher,
+		BatchSize: 100,             // Default batch size 100 events
+		Pace:      time.Second * 1, // Default pace 1 second
+	}
+}
+
+// Run publish events from the outbox until the context is cancelled or the publisher returns an error.
+func (r *Relay) Run(ctx context.Context) error {
+	for {
+		delivered, err := r.RunOnce(ctx)
+		if err != nil {
+			return err
+		}
+		// continue direct if there could be more events in the outbox
+		if delivered > 0 {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(r.Pace):
+		}
+	}
+}
+
+// RunOnce publish one batch of undelivered events and returns the number of delivered events.
+// If the publisher returns an error the events before it are removed from the outbox and the rest
+// is kept in the outbox to be published in a later run.
+func (r *Relay) RunOnce(ctx context.Context) (int, error) {
+	events, err := r.undelivered(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for i, event := range events {
+		err = r.publisher.Publish(ctx, event)
+		if err != nil {
+			return i, err
+		}
+		err = r.store.deleteFromOutbox(ctx, event.GlobalVersion)
+		if err != nil {
+			return i, err
+		}
+	}
+	return len(events), nil
+}
+
+// undelivered fetch the events in the outbox that are not delivered.
+// The rows are read before returning to release the connection before events are removed from the outbox.
+func (r *Relay) undelivered(ctx context.Context) ([]core.Event, error) {
+	selectStm := r.store.dialect.Rebind(`Select e.seq, e.id, e.version, e.reason, e.type, e.timestamp, e.timestamp_nano, e.data, e.metadata from ` + r.store.table("outbox") + ` o join ` + r.store.table("events") + ` e on e.seq = o.seq order by o.seq asc LIMIT ?`)
+	rows, err := r.store.db.QueryContext(ctx, selectStm, r.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	i := iterator{rows: rows}
+	defer i.Close()
+
+	events := make([]core.Event, 0)
+	for i.Next() {
+		event, err := i.Value()
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+// deleteFromOutbox removes the delivered event from the outbox, keeping the outbox to the undelivered events
+func (s *SQL) deleteFromOutbox(ctx context.Context, globalVersion core.Version) error {
+	if s.lock != nil {
+		// prevent multiple writers
+		s.lock.Lock()
+		defer s.lock.Unlock()
+	}
+	_, err := s.db.ExecContext(ctx, s.dialect.Rebind(`Delete from `+s.table("outbox")+` where seq = ?`), globalVersion)
+	return err
+}
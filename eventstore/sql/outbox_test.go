@@ -0,0 +1,168 @@
+package sql_test
+
+import (
+	"context"
+	sqldriver "database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/eventstore/sql"
+)
+
+type publisher struct {
+	events []core.Event
+	err    error
+}
+
+func (p *publisher) Publish(ctx context.Context, event core.Event) error {
+	if p.err != nil {
+		return p.err
+	}
+	p.events = append(p.events, event)
+	return nil
+}
+
+func outboxEvents(id string) []core.Event {
+	return []core.Event{
+		{AggregateID: id, Version: 1, AggregateType: "Person", Reason: "Born", Timestamp: time.Now(), Data: []byte("{}")},
+		{AggregateID: id, Version: 2, AggregateType: "Person", Reason: "AgedOneYear", Timestamp: time.Now(), Data: []byte("{}")},
+	}
+}
+
+func outboxEventstore(t *testing.T, options ...sql.Option) *sql.SQL {
+	db, err := sqldriver.Open("sqlite3", "file:outbox?mode=memory&cache=shared")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	es := sql.Open(db, options...)
+	err = es.Migrate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(es.Close)
+	return es
+}
+
+func TestRelayPublishSavedEvents(t *testing.T) {
+	es := outboxEventstore(t, sql.WithOutbox())
+	err := es.Save(outboxEvents("1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := &publisher{}
+	relay := es.Relay(p)
+	delivered, err := relay.RunOnce(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if delivered != 2 {
+		t.Fatalf("expected 2 delivered events got %d", delivered)
+	}
+	if p.events[0].Reason != "Born" || p.events[1].Reason != "AgedOneYear" {
+		t.Fatalf("events published in wrong order %v", p.events)
+	}
+
+	// delivered events should not be published again
+	delivered, err = relay.RunOnce(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if delivered != 0 {
+		t.Fatalf("expected no delivered events got %d", delivered)
+	}
+}
+
+func TestRelayRepublishOnError(t *testing.T) {
+	es := outboxEventstore(t, sql.WithOutbox())
+	err := es.Save(outboxEvents("1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errPublish := errors.New("publish error")
+	p := &publisher{err: errPublish}
+	relay := es.Relay(p)
+	_, err = relay.RunOnce(context.Background())
+	if !errors.Is(err, errPublish) {
+		t.Fatalf("expected publish error got %v", err)
+	}
+
+	// the events are still in the outbox
+	p.err = nil
+	delivered, err := relay.RunOnce(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if delivered != 2 {
+		t.Fatalf("expected 2 delivered events got %d", delivered)
+	}
+}
+
+func TestNoOutbox(t *testing.T) {
+	es := outboxEventstore(t)
+	err := es.Save(outboxEvents("1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := &publisher{}
+	delivered, err := es.Relay(p).RunOnce(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if delivered != 0 {
+		t.Fatalf("expected no delivered events got %d", delivered)
+	}
+}
+
+func TestRelayRun(t *testing.T) {
+	es := outboxEventstore(t, sql.WithOutbox())
+	err := es.Save(outboxEvents("1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*100)
+	defer cancel()
+
+	p := &publisher{}
+	relay := es.Relay(p)
+	relay.Pace = time.Millisecond * 10
+	err = relay.Run(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatal(err)
+	}
+	if len(p.events) != 2 {
+		t.Fatalf("expected 2 published events got %d", len(p.events))
+	}
+}
+
+func TestRelayRemovesDeliveredEvents(t *testing.T) {
+	db := openDB(t, "outboxremove")
+	es := sql.Open(db, sql.WithOutbox())
+	err := es.Migrate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = es.Save(outboxEvents("1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = es.Relay(&publisher{}).RunOnce(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var count int
+	err = db.QueryRow(`select count(*) from outbox`).Scan(&count)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Fatalf("expected an empty outbox got %d events", count)
+	}
+}
@@ -0,0 +1,162 @@
+package sql_test
+
+import (
+	"context"
+	sqldriver "database/sql"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/core/testsuite"
+	"github.com/hallgren/eventsourcing/eventstore/sql"
+)
+
+func TestSuitePageSize(t *testing.T) {
+	f := func() (core.EventStore, func(), error) {
+		db, err := sqldriver.Open("sqlite3", "file::memory:?cache=shared")
+		if err != nil {
+			return nil, nil, err
+		}
+		db.SetMaxOpenConns(1)
+		// the small page size makes the aggregates span several pages
+		es := sql.Open(db, sql.WithPageSize(2))
+		err = es.Migrate()
+		if err != nil {
+			return nil, nil, err
+		}
+		return es, es.Close, nil
+	}
+	testsuite.Test(t, f)
+}
+
+// personEvents returns count events on the person
+func personEvents(id string, count int) []core.Event {
+	events := make([]core.Event, count)
+	for i := range events {
+		events[i] = core.Event{AggregateID: id, Version: core.Version(i + 1), AggregateType: "Person", Reason: "AgedOneYear", Timestamp: time.Now(), Data: []byte("{}")}
+	}
+	return events
+}
+
+func TestPageSizeGet(t *testing.T) {
+	es := sql.Open(openDB(t, "pageget"), sql.WithPageSize(3))
+	err := es.Migrate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = es.Save(personEvents("123", 10))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	iter, err := es.Get(context.Background(), "123", "Person", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer iter.Close()
+	expected := core.Version(3)
+	for iter.Next() {
+		event, err := iter.Value()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if event.Version != expected {
+			t.Fatalf("expected version %d got %d", expected, event.Version)
+		}
+		// the connection is released between the pages, with a single connection a save would block
+		// if the iterator held a cursor
+		if event.Version%3 == 0 {
+			err = es.Save(personEvents(fmt.Sprintf("other-%d", event.Version), 1))
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		expected++
+	}
+	if expected != 11 {
+		t.Fatalf("expected the last version to be 10 got %d", expected-1)
+	}
+}
+
+func TestPageSizeAll(t *testing.T) {
+	es := sql.Open(openDB(t, "pageall"), sql.WithPageSize(3))
+	err := es.Migrate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = es.Save(personEvents("123", 10))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		start    core.Version
+		count    uint64
+		expected []core.Version
+	}{
+		{start: 0, count: 100, expected: []core.Version{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}},
+		{start: 1, count: 3, expected: []core.Version{1, 2, 3}},
+		{start: 4, count: 4, expected: []core.Version{4, 5, 6, 7}},
+		{start: 9, count: 100, expected: []core.Version{9, 10}},
+		{start: 11, count: 100, expected: []core.Version{}},
+		{start: 1, count: 0, expected: []core.Version{}},
+	}
+	for _, test := range tests {
+		iter, err := es.All(test.start, test.count)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := []core.Version{}
+		for iter.Next() {
+			event, err := iter.Value()
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, event.GlobalVersion)
+		}
+		iter.Close()
+		if fmt.Sprint(got) != fmt.Sprint(test.expected) {
+			t.Fatalf("start %d count %d expected %v got %v", test.start, test.count, test.expected, got)
+		}
+	}
+}
+
+func TestPageSizeContextCancel(t *testing.T) {
+	es := sql.Open(openDB(t, "pagecancel"), sql.WithPageSize(2))
+	err := es.Migrate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = es.Save(personEvents("123", 10))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	iter, err := es.AllWithContext(ctx, 1, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer iter.Close()
+	read := 0
+	for iter.Next() {
+		_, err = iter.Value()
+		if err != nil {
+			break
+		}
+		read++
+		if read == 3 {
+			cancel()
+		}
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context canceled got %v", err)
+	}
+	// the events of the page read before the cancel are returned
+	if read != 4 {
+		t.Fatalf("expected 4 read events got %d", read)
+	}
+}
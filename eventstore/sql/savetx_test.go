@@ -0,0 +1,120 @@
+package sql_test
+
+import (
+	"context"
+	sqldriver "database/sql"
+	"errors"
+	"testing"
+
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/eventstore/sql"
+)
+
+// saveTxEventstore returns an event store with a table holding unique names that is updated in the same
+// transaction as the events are saved
+func saveTxEventstore(t *testing.T, name string, options ...sql.Option) (*sql.SQL, *sqldriver.DB) {
+	db := openDB(t, name)
+	es := sql.Open(db, options...)
+	err := es.Migrate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.Exec(`create table names (name VARCHAR NOT NULL PRIMARY KEY)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return es, db
+}
+
+// saveWithName saves the events and the name in one transaction
+func saveWithName(es *sql.SQL, db *sqldriver.DB, name string, events []core.Event) error {
+	ctx := context.Background()
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	_, err = tx.ExecContext(ctx, `insert into names (name) values (?)`, name)
+	if err != nil {
+		return err
+	}
+	err = es.SaveTx(ctx, tx, events)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func countEvents(t *testing.T, es *sql.SQL, id string) int {
+	iter, err := es.Get(context.Background(), id, "Person", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer iter.Close()
+	count := 0
+	for iter.Next() {
+		count++
+	}
+	return count
+}
+
+func TestSaveTx(t *testing.T) {
+	es, db := saveTxEventstore(t, "savetx")
+
+	events := outboxEvents("123")
+	err := saveWithName(es, db, "kalle", events)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if events[0].GlobalVersion != 1 || events[1].GlobalVersion != 2 {
+		t.Fatalf("expected global version 1 and 2 got %d and %d", events[0].GlobalVersion, events[1].GlobalVersion)
+	}
+	if count := countEvents(t, es, "123"); count != 2 {
+		t.Fatalf("expected 2 events got %d", count)
+	}
+}
+
+func TestSaveTxRollback(t *testing.T) {
+	es, db := saveTxEventstore(t, "savetxrollback", sql.WithOutbox())
+
+	err := saveWithName(es, db, "kalle", outboxEvents("123"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the name is taken which makes the transaction roll back
+	err = saveWithName(es, db, "kalle", outboxEvents("456"))
+	if err == nil {
+		t.Fatal("expected the name to be taken")
+	}
+	if count := countEvents(t, es, "456"); count != 0 {
+		t.Fatalf("expected no events after rollback got %d", count)
+	}
+	head, err := es.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if head != 2 {
+		t.Fatalf("expected head 2 got %d", head)
+	}
+}
+
+func TestSaveTxConcurrency(t *testing.T) {
+	es, db := saveTxEventstore(t, "savetxconcurrency", sql.WithCommitOrder())
+
+	err := es.Save(outboxEvents("123"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = saveWithName(es, db, "kalle", outboxEvents("123"))
+	if !errors.Is(err, core.ErrConcurrency) {
+		t.Fatalf("expected concurrency error got %v", err)
+	}
+	var count int
+	err = db.QueryRow(`select count(*) from names`).Scan(&count)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Fatalf("expected the name to be rolled back got %d names", count)
+	}
+}
@@ -5,25 +5,113 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"math"
 	"sync"
-	"time"
 
 	"github.com/hallgren/eventsourcing/core"
 )
 
 // SQL event store handler
 type SQL struct {
-	db   *sql.DB
-	lock *sync.Mutex
+	db      *sql.DB
+	lock    *sync.Mutex
+	outbox  bool
+	dialect Dialect
+	schema  string
+	prefix  string
+	// commitOrder makes the writers assign the global version in commit order
+	commitOrder bool
+	// pageSize makes Get and All read the events in pages of the size
+	pageSize uint64
+	// batchSize is the max number of events in one insert statement
+	batchSize int
+	// stmts holds the statements of Save prepared on the database
+	stmts     map[string]*sql.Stmt
+	stmtsLock sync.Mutex
 }
 
-// Open connection to database
-func Open(db *sql.DB) *SQL {
-	return &SQL{
-		db: db,
+// Option configures the SQL event store
+type Option func(s *SQL)
+
+// WithOutbox makes Save write a reference to every saved event into the outbox table in the same
+// transaction as the events. The outbox is consumed by a Relay.
+func WithOutbox() Option {
+	return func(s *SQL) {
+		s.outbox = true
+	}
+}
+
+// WithDialect sets the database dialect and is the supported way to choose it. If not set the dialect is based on
+// the type of the database driver, with SQLite as fallback, kept for backward compatibility.
+func WithDialect(dialect Dialect) Option {
+	return func(s *SQL) {
+		s.dialect = dialect
+	}
+}
+
+// WithCommitOrder makes the global version (seq) of the saved events follow the commit order. An autoincrement seq is
+// assigned on insert and a transaction that commits after a concurrent transaction with a higher seq makes its events
+// visible behind the position of a reader, making the reader skip them. With commit order the writers take a lock
+// and assign the seq after the last saved event, i.e. a reader never sees a gap that is filled later.
+//
+// The writes to the event store are serialized, and all writers has to use the option.
+func WithCommitOrder() Option {
+	return func(s *SQL) {
+		s.commitOrder = true
+	}
+}
+
+// WithPageSize makes Get and All read the events in pages of the size instead of in one query. The pages are read
+// with a keyset query on the version and global version and the connection is released between the pages, e.g. to
+// not hold a cursor and a connection while a long event stream is replayed.
+func WithPageSize(size uint64) Option {
+	return func(s *SQL) {
+		s.pageSize = size
+	}
+}
+
+// WithBatchSize sets the max number of events that Save inserts in one multi-row insert, default 100. A batch size
+// of one inserts the events one by one. The MySQL dialect does not return the global version of each row in a
+// multi-row insert and inserts the events one by one unless the WithCommitOrder option is used.
+func WithBatchSize(size int) Option {
+	return func(s *SQL) {
+		s.batchSize = size
+	}
+}
+
+// WithSchema places the tables in the database schema
+func WithSchema(schema string) Option {
+	return func(s *SQL) {
+		s.schema = schema
+	}
+}
+
+// WithTablePrefix adds the prefix to the table and index names, e.g. to host several event stores in one database
+func WithTablePrefix(prefix string) Option {
+	return func(s *SQL) {
+		s.prefix = prefix
 	}
 }
 
+// Open connection to database. Set the dialect of the database with the WithDialect option.
+func Open(db *sql.DB, options ...Option) *SQL {
+	s := &SQL{
+		db:        db,
+		batchSize: 100,
+		stmts:     make(map[string]*sql.Stmt),
+	}
+	for _, option := range options {
+		option(s)
+	}
+	if s.batchSize < 1 {
+		s.batchSize = 1
+	}
+	if s.dialect.name == "" {
+		s.dialect = dialectFromDriver(db.Driver())
+	}
+	return s
+}
+
 // OpenWithSingelWriter prevents multiple writers to save events concurrently
 //
 // Multiple go routines writing concurrently to sqlite could produce sqlite to lock.
@@ -34,15 +122,20 @@ func Open(db *sql.DB) *SQL {
 // or some other mechanism that supports blocking to ensure that at most one
 // writer is attempting to COMMIT a BEGIN CONCURRENT transaction at a time.
 // This is usually easier if all writers are part of the same operating system process."
-func OpenWithSingelWriter(db *sql.DB) *SQL {
-	return &SQL{
-		db:   db,
-		lock: &sync.Mutex{},
-	}
+func OpenWithSingelWriter(db *sql.DB, options ...Option) *SQL {
+	s := Open(db, options...)
+	s.lock = &sync.Mutex{}
+	return s
 }
 
-// Close the connection
+// Close the prepared statements and the connection
 func (s *SQL) Close() {
+	s.stmtsLock.Lock()
+	for _, stmt := range s.stmts {
+		stmt.Close()
+	}
+	s.stmts = make(map[string]*sql.Stmt)
+	s.stmtsLock.Unlock()
 	s.db.Close()
 }
 
@@ -58,19 +151,59 @@ func (s *SQL) Save(events []core.Event) error {
 		s.lock.Lock()
 		defer s.lock.Unlock()
 	}
-	aggregateID := events[0].AggregateID
-	aggregateType := events[0].AggregateType
 
-	tx, err := s.db.BeginTx(context.Background(), nil)
+	ctx := context.Background()
+	err := s.prepareSave(ctx, len(events))
+	if err != nil {
+		return err
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return errors.New(fmt.Sprintf("could not start a write transaction, %v", err))
 	}
 	defer tx.Rollback()
 
+	err = s.save(ctx, tx, events)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// SaveTx persists events in the transaction, e.g. to update other tables atomically with the events. The
+// concurrency check is made in the transaction and the events are visible to others first when the caller commits.
+// The global versions are set on the events when SaveTx returns but they are only valid if the transaction is committed.
+//
+// SaveTx does not take the single writer lock as the transaction is controlled by the caller. It uses the statements
+// prepared by Save and executes the other queries directly, as a prepare outside the transaction would wait for a
+// connection held by the caller when the connection pool is exhausted.
+func (s *SQL) SaveTx(ctx context.Context, tx *sql.Tx, events []core.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	return s.save(ctx, tx, events)
+}
+
+// save persists events in the transaction
+func (s *SQL) save(ctx context.Context, tx *sql.Tx, events []core.Event) error {
+	aggregateID := events[0].AggregateID
+	aggregateType := events[0].AggregateType
+
+	stmts := newStatements(ctx, tx, s)
+	defer stmts.close()
+
+	var seq int64
+	var err error
+	if s.commitOrder {
+		seq, err = s.lockSequence(stmts)
+		if err != nil {
+			return err
+		}
+	}
+
 	var currentVersion core.Version
 	var version int
-	selectStm := `Select version from events where id=? and "type"=? order by version desc limit 1`
-	err = tx.QueryRow(selectStm, aggregateID, aggregateType).Scan(&version)
+	err = stmts.queryRow(s.versionQuery(), aggregateID, aggregateType).Scan(&version)
 	if err != nil && err != sql.ErrNoRows {
 		return err
 	} else if err == sql.ErrNoRows {
@@ -86,26 +219,59 @@ func (s *SQL) Save(events []core.Event) error {
 		return core.ErrConcurrency
 	}
 
-	var lastInsertedID int64
-	insert := `Insert into events (id, version, reason, type, timestamp, data, metadata) values ($1, $2, $3, $4, $5, $6, $7)`
-	for i, event := range events {
-		res, err := tx.Exec(insert, event.AggregateID, event.Version, event.Reason, event.AggregateType, event.Timestamp.Format(time.RFC3339), event.Data, event.Metadata)
-		if err != nil {
-			return err
+	batchSize := s.insertBatchSize()
+	for from := 0; from < len(events); from += batchSize {
+		to := from + batchSize
+		if to > len(events) {
+			to = len(events)
+		}
+		// the GlobalVersion is set on the events in the batch which exposes it to the caller
+		batch := events[from:to]
+		if s.commitOrder {
+			err = s.insertWithSeq(stmts, seq, batch)
+			seq += int64(len(batch))
+		} else {
+			err = s.insert(stmts, batch)
 		}
-		lastInsertedID, err = res.LastInsertId()
 		if err != nil {
 			return err
 		}
-		// override the event in the slice exposing the GlobalVersion to the caller
-		events[i].GlobalVersion = core.Version(lastInsertedID)
+		if s.outbox {
+			err = s.insertOutbox(stmts, batch)
+			if err != nil {
+				return err
+			}
+		}
 	}
-	return tx.Commit()
+	return nil
+}
+
+// lockSequence takes the sequence lock that is held until the transaction ends and returns the seq of the last saved event
+func (s *SQL) lockSequence(stmts *statements) (int64, error) {
+	_, err := stmts.exec(s.lockQuery())
+	if err != nil {
+		return 0, err
+	}
+	var seq int64
+	err = stmts.queryRow(s.lastSeqQuery()).Scan(&seq)
+	return seq, err
 }
 
 // Get the events from database
 func (s *SQL) Get(ctx context.Context, id string, aggregateType string, afterVersion core.Version) (core.Iterator, error) {
-	selectStm := `Select seq, id, version, reason, "type", timestamp, data, metadata from events where id=? and "type"=? and version>? order by version asc`
+	if s.pageSize > 0 {
+		return &pageIterator{
+			ctx:      ctx,
+			db:       s.db,
+			query:    s.dialect.Rebind(`Select seq, id, version, reason, type, timestamp, timestamp_nano, data, metadata from ` + s.table("events") + ` where id=? and type=? and version>? order by version asc LIMIT ?`),
+			args:     []interface{}{id, aggregateType},
+			key:      func(e core.Event) core.Version { return e.Version },
+			after:    afterVersion,
+			pageSize: s.pageSize,
+			left:     math.MaxUint64,
+		}, nil
+	}
+	selectStm := s.dialect.Rebind(`Select seq, id, version, reason, type, timestamp, timestamp_nano, data, metadata from ` + s.table("events") + ` where id=? and type=? and version>? order by version asc`)
 	rows, err := s.db.QueryContext(ctx, selectStm, id, aggregateType, afterVersion)
 	if err != nil {
 		return nil, err
@@ -115,10 +281,53 @@ func (s *SQL) Get(ctx context.Context, id string, aggregateType string, afterVer
 
 // All iterate over all event in GlobalEvents order
 func (s *SQL) All(start core.Version, count uint64) (core.Iterator, error) {
-	selectStm := `Select seq, id, version, reason, "type", timestamp, data, metadata from events where seq >= ? order by seq asc LIMIT ?`
-	rows, err := s.db.Query(selectStm, start, count)
+	return s.AllWithContext(context.Background(), start, count)
+}
+
+// AllWithContext iterate over all event in GlobalEvents order. The reading can be canceled from the outside.
+func (s *SQL) AllWithContext(ctx context.Context, start core.Version, count uint64) (core.Iterator, error) {
+	if s.pageSize > 0 {
+		var after core.Version
+		if start > 0 {
+			after = start - 1
+		}
+		return &pageIterator{
+			ctx:      ctx,
+			db:       s.db,
+			query:    s.dialect.Rebind(`Select seq, id, version, reason, type, timestamp, timestamp_nano, data, metadata from ` + s.table("events") + ` where seq > ? order by seq asc LIMIT ?`),
+			key:      func(e core.Event) core.Version { return e.GlobalVersion },
+			after:    after,
+			pageSize: s.pageSize,
+			left:     count,
+		}, nil
+	}
+	selectStm := s.dialect.Rebind(`Select seq, id, version, reason, type, timestamp, timestamp_nano, data, metadata from ` + s.table("events") + ` where seq >= ? order by seq asc LIMIT ?`)
+	rows, err := s.db.QueryContext(ctx, selectStm, start, count)
 	if err != nil {
 		return nil, err
 	}
 	return &iterator{rows: rows}, nil
 }
+
+// table returns the table name with the prefix and schema
+func (s *SQL) table(name string) string {
+	if s.schema != "" {
+		return s.schema + "." + s.prefix + name
+	}
+	return s.prefix + name
+}
+
+// createIndex returns the statement that creates the index on the table with the prefix and schema
+func (s *SQL) createIndex(unique bool, index, table, columns string) string {
+	return s.dialect.CreateIndex(unique, s.schema, s.prefix+index, s.prefix+table, columns)
+}
+
+// Head returns the global version of the last saved event
+func (s *SQL) Head() (core.Version, error) {
+	var head sql.NullInt64
+	err := s.db.QueryRow(`Select max(seq) from ` + s.table("events")).Scan(&head)
+	if err != nil {
+		return 0, err
+	}
+	return core.Version(head.Int64), nil
+}
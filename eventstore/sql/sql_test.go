@@ -64,3 +64,107 @@ func eventstore(singelWriter bool) (*sql.SQL, func(), error) {
 		es.Close()
 	}, nil
 }
+
+func TestHead(t *testing.T) {
+	es, close, err := eventstore(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer close()
+
+	head, err := es.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if head != 0 {
+		t.Fatalf("expected head 0 on empty event store got %d", head)
+	}
+
+	events := outboxEvents("1")
+	err = es.Save(events)
+	if err != nil {
+		t.Fatal(err)
+	}
+	head, err = es.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if head != events[1].GlobalVersion {
+		t.Fatalf("expected head %d got %d", events[1].GlobalVersion, head)
+	}
+}
+
+func TestSuiteTablePrefix(t *testing.T) {
+	f := func() (core.EventStore, func(), error) {
+		db, err := sqldriver.Open("sqlite3", "file::memory:?cache=shared")
+		if err != nil {
+			return nil, nil, err
+		}
+		db.SetMaxOpenConns(1)
+		es := sql.Open(db, sql.WithTablePrefix("orders_"), sql.WithOutbox())
+		err = es.Migrate()
+		if err != nil {
+			return nil, nil, err
+		}
+		return es, es.Close, nil
+	}
+	testsuite.Test(t, f)
+}
+
+func TestSuiteSchema(t *testing.T) {
+	f := func() (core.EventStore, func(), error) {
+		db, err := sqldriver.Open("sqlite3", "file::memory:?cache=shared")
+		if err != nil {
+			return nil, nil, err
+		}
+		// the attached database is the schema in sqlite
+		db.SetMaxOpenConns(1)
+		_, err = db.Exec(`attach database 'file:ctx?mode=memory&cache=shared' as ctx`)
+		if err != nil {
+			return nil, nil, err
+		}
+		es := sql.Open(db, sql.WithSchema("ctx"), sql.WithOutbox())
+		err = es.Migrate()
+		if err != nil {
+			return nil, nil, err
+		}
+		return es, es.Close, nil
+	}
+	testsuite.Test(t, f)
+}
+
+func TestTablePrefixSeparatesEventStores(t *testing.T) {
+	db, err := sqldriver.Open("sqlite3", "file:prefix?mode=memory&cache=shared")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	defer db.Close()
+
+	orders := sql.Open(db, sql.WithTablePrefix("orders_"))
+	users := sql.Open(db, sql.WithTablePrefix("users_"))
+	for _, es := range []*sql.SQL{orders, users} {
+		err = es.Migrate()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = orders.Save(outboxEvents("1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	head, err := users.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if head != 0 {
+		t.Fatalf("expected no events in the users event store got head %d", head)
+	}
+	head, err = orders.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if head != 2 {
+		t.Fatalf("expected head 2 in the orders event store got %d", head)
+	}
+}
@@ -0,0 +1,120 @@
+package eventsourcing
+
+import (
+	"context"
+	"hash/fnv"
+	"sync"
+
+	"github.com/hallgren/eventsourcing/core"
+)
+
+// partitionItem is an event with its index in the fetched batch
+type partitionItem struct {
+	index int
+	event Event
+}
+
+// runPartitioned handles the events in parallel. The events are hashed by aggregate ID into partitions where each
+// partition handles its events in order, preserving the order of events within an aggregate.
+//
+// When a partition fails it stops handling events, and the position only advances to the last event where all events
+// before it are handled. Events after the position that were handled by other partitions will be handled again in the
+// next run.
+func (p *Projection) runPartitioned(ctx context.Context, iterator core.Iterator) (bool, Event, error) {
+	var ran bool
+	var lastHandledEvent Event
+
+	// read the batch before it's distributed to the partitions
+	events := make([]core.Event, 0)
+	var fetchErr error
+	for iterator.Next() {
+		ran = true
+		event, err := iterator.Value()
+		if err != nil {
+			fetchErr = err
+			break
+		}
+		events = append(events, event)
+	}
+
+	handled := make([]bool, len(events))
+	decoded := make([]Event, len(events))
+	failedAt := make([]int, p.Partitions)
+	failures := make([]error, p.Partitions)
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+
+	partitions := make([]chan partitionItem, p.Partitions)
+	for i := range partitions {
+		partitions[i] = make(chan partitionItem, len(events))
+		wg.Add(1)
+		go func(partition int) {
+			defer wg.Done()
+			for item := range partitions[partition] {
+				// keep the order in the partition by not handling events after a failure
+				if failures[partition] != nil {
+					continue
+				}
+				err := p.handle(ctx, item.event)
+				if err != nil {
+					failures[partition] = err
+					failedAt[partition] = item.index
+					continue
+				}
+				lock.Lock()
+				handled[item.index] = true
+				lock.Unlock()
+			}
+		}(i)
+	}
+
+	// the index of the event that could not be decoded
+	errAt := len(events)
+	for i, event := range events {
+		e, found, err := p.decode(event)
+		if err != nil {
+			fetchErr = err
+			errAt = i
+			break
+		}
+		if !found {
+			handled[i] = true
+			continue
+		}
+		decoded[i] = e
+		partitions[partition(event.AggregateID, p.Partitions)] <- partitionItem{index: i, event: e}
+	}
+	for _, partition := range partitions {
+		close(partition)
+	}
+	wg.Wait()
+
+	// advance the position to the last event where all events before it are handled
+	for i, event := range events {
+		if !handled[i] {
+			break
+		}
+		p.setPosition(event.GlobalVersion)
+		if decoded[i].data != nil {
+			lastHandledEvent = decoded[i]
+		}
+	}
+
+	// return the error from the partition that failed first in the event order
+	err := fetchErr
+	first := errAt
+	for i, failure := range failures {
+		if failure != nil && failedAt[i] < first {
+			first = failedAt[i]
+			err = failure
+		}
+	}
+	return ran, lastHandledEvent, err
+}
+
+// partition returns the partition the aggregate belongs to
+func partition(aggregateID string, partitions int) int {
+	h := fnv.New32a()
+	h.Write([]byte(aggregateID))
+	return int(h.Sum32() % uint32(partitions))
+}
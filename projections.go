@@ -12,7 +12,10 @@ import (
 )
 
 type fetchFunc func() (core.Iterator, error)
+type fetchFromFunc func(start core.Version) (core.Iterator, error)
+type headFunc func() (core.Version, error)
 type callbackFunc func(e Event) error
+type batchCallbackFunc func(batch Batch) error
 
 type ProjectionHandler struct {
 	register *Register
@@ -23,6 +26,12 @@ type ProjectionHandler struct {
 // ErrProjectionAlreadyRunning is returned if Run is called on an already running projection
 var ErrProjectionAlreadyRunning = errors.New("projection is already running")
 
+// ErrPartitionsBatchProjection is returned if a batch projection is run with partitions
+var ErrPartitionsBatchProjection = errors.New("partitions is not supported by a batch projection")
+
+// ErrCheckpointProjection is returned if a projection created with Projection is run with a checkpoint store
+var ErrCheckpointProjection = errors.New("checkpoint is not supported by a projection where the fetch func keeps track of its own position, use ProjectionFrom")
+
 func NewProjectionHandler(register *Register, encoder encoder) *ProjectionHandler {
 	return &ProjectionHandler{
 		register: register,
@@ -31,13 +40,43 @@ func NewProjectionHandler(register *Register, encoder encoder) *ProjectionHandle
 }
 
 type Projection struct {
-	running   atomic.Bool
-	fetchF    fetchFunc
-	callbackF callbackFunc
-	handler   *ProjectionHandler
-	trigger   chan func()
-	Strict    bool // Strict indicate if the projection should return error if the event it fetches is not found in the register
-	Name      string
+	running         atomic.Bool
+	lock            sync.Mutex
+	fetchF          fetchFromFunc
+	callbackF       callbackFunc
+	batchCallbackF  batchCallbackFunc
+	handler         *ProjectionHandler
+	trigger         chan func()
+	position        core.Version // the global version of the last handled event
+	lastError       error        // the error from the last run
+	lastEventAt     time.Time    // when the projection last handled an event
+	eventsPerSecond float64      // the rate events were handled in the last run that handled events
+	loaded          bool         // true when the position is loaded from the checkpoint store
+	ownPosition     bool         // true when the fetch func keeps track of its own position and ignores the start
+	batches         int          // handled batches since the last saved checkpoint
+	Strict          bool         // Strict indicate if the projection should return error if the event it fetches is not found in the register
+	Name            string
+	Version         int             // Version of the projection, separates the checkpoint and dead letters when a projection is rebuilt
+	Checkpoint      CheckpointStore // Checkpoint stores the projection position by its Name. The position is loaded the first time the projection runs. Only supported by a projection created with ProjectionFrom or BatchProjection
+	CheckpointEvery int             // CheckpointEvery is the number of handled batches between each stored checkpoint
+	Retry           RetryPolicy     // Retry sets how many times and how often a failing callback is retried
+	DeadLetters     DeadLetterStore // DeadLetters parks events that still fails after the retries, making the projection continue with the next event
+	Partitions      int             // Partitions is the number of workers handling events in parallel, partitioned by aggregate ID. The callback is called concurrently and must be safe for concurrent use. Not supported by a batch projection. Default 0 (sequential)
+	BatchSize       int             // BatchSize is the max number of events in a batch handled by a batch projection
+	BatchTimeout    time.Duration   // BatchTimeout is the max time events are collected into a batch before it's handled, no limit if zero
+	// CallbackCheckpoint indicate that the batch callback stores the checkpoint, e.g. in the same transaction as the read-model.
+	// The projection only loads the checkpoint.
+	CallbackCheckpoint bool
+	Restart            RestartStrategy // Restart decides if a failing projection in a group is restarted. Default nil (never restarted)
+	Clock              Clock           // Clock is used for the pace, retries and status. Default the global clock set by SetClock
+}
+
+// clock returns the projection clock or the global clock if not set
+func (p *Projection) clock() Clock {
+	if p.Clock != nil {
+		return p.Clock
+	}
+	return clock
 }
 
 // Group runs projections concurrently
@@ -45,11 +84,21 @@ type Group struct {
 	Pace        time.Duration // Pace is used when a projection is running and it reaches the end of the event stream
 	handler     *ProjectionHandler
 	projections []*Projection
+	ctx         context.Context
 	cancelF     context.CancelFunc
+	running     map[*Projection]groupMember
+	lock        sync.Mutex
+	Head        headFunc // Head returns the global version of the last event in the event store, used to calculate the projection lag
 	wg          sync.WaitGroup
 	ErrChan     chan error
 }
 
+// groupMember holds the state of a running projection in the group
+type groupMember struct {
+	cancelF context.CancelFunc
+	done    chan struct{}
+}
+
 // ProjectionResult is the return type for a Group and Race
 type ProjectionResult struct {
 	Error            error
@@ -59,18 +108,62 @@ type ProjectionResult struct {
 
 // Projection creates a projection that will run down an event stream
 func (ph *ProjectionHandler) Projection(fetchF fetchFunc, callbackF callbackFunc) *Projection {
+	projection := ph.ProjectionFrom(func(start core.Version) (core.Iterator, error) {
+		// the fetch func keeps track of its own position
+		return fetchF()
+	}, callbackF)
+	projection.ownPosition = true
+	return projection
+}
+
+// ProjectionFrom creates a projection that keeps track of its position in the event stream. The fetch func
+// is called with the global version of the next event to fetch.
+func (ph *ProjectionHandler) ProjectionFrom(fetchF fetchFromFunc, callbackF callbackFunc) *Projection {
 	projection := Projection{
-		fetchF:    fetchF,
-		callbackF: callbackF,
-		handler:   ph,
-		trigger:   make(chan func()),
-		Strict:    true,                        // Default strict is active
-		Name:      fmt.Sprintf("%d", ph.count), // Default the name to it's creation index
+		fetchF:          fetchF,
+		callbackF:       callbackF,
+		handler:         ph,
+		trigger:         make(chan func()),
+		Strict:          true,                        // Default strict is active
+		Name:            fmt.Sprintf("%d", ph.count), // Default the name to it's creation index
+		CheckpointEvery: 1,                           // Default store the checkpoint after each batch
 	}
 	ph.count++
 	return &projection
 }
 
+// BatchProjection creates a projection where the callback handles a batch of events. If the callback returns an error
+// none of the events in the batch are seen as handled.
+func (ph *ProjectionHandler) BatchProjection(fetchF fetchFromFunc, callbackF batchCallbackFunc) *Projection {
+	projection := ph.ProjectionFrom(fetchF, nil)
+	projection.batchCallbackF = callbackF
+	projection.BatchSize = 100 // Default 100 events in a batch
+	return projection
+}
+
+// Position returns the global version of the last event handled by the projection
+func (p *Projection) Position() Version {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return Version(p.position)
+}
+
+// setPosition sets the global version of the last handled event
+func (p *Projection) setPosition(position core.Version) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.position = position
+}
+
+// Key returns the name the projection checkpoint and dead letters are stored by. The version is added
+// to the name when set, separating the state of different versions of the same projection.
+func (p *Projection) Key() string {
+	if p.Version == 0 {
+		return p.Name
+	}
+	return fmt.Sprintf("%s_v%d", p.Name, p.Version)
+}
+
 // TriggerAsync force a running projection to run immediately independent on the pace
 // It will return immediately after triggering the prjection to run.
 // If the trigger channel is already filled it will return without inserting any value.
@@ -102,10 +195,14 @@ func (p *Projection) TriggerSync() {
 // Run runs the projection forever until the context is cancelled. When there are no more events to consume it
 // waits for a trigger or context cancel.
 func (p *Projection) Run(ctx context.Context, pace time.Duration) error {
-	if p.running.Load() {
+	if !p.running.CompareAndSwap(false, true) {
 		return ErrProjectionAlreadyRunning
 	}
-	p.running.Store(true)
+	return p.run(ctx, pace)
+}
+
+// run runs the projection on the caller that has set it as running
+func (p *Projection) run(ctx context.Context, pace time.Duration) error {
 	defer func() {
 		p.running.Store(false)
 	}()
@@ -128,7 +225,7 @@ func (p *Projection) Run(ctx context.Context, pace time.Duration) error {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
-		case <-time.After(pace):
+		case <-p.clock().After(pace):
 		case f = <-p.trigger:
 		}
 	}
@@ -144,7 +241,7 @@ func (p *Projection) RunToEnd(ctx context.Context) ProjectionResult {
 		case <-ctx.Done():
 			return ProjectionResult{Error: ctx.Err(), Name: result.Name, LastHandledEvent: result.LastHandledEvent}
 		default:
-			ran, result := p.RunOnce()
+			ran, result := p.runOnce(ctx)
 			// if the first event returned error or if it did not run at all
 			if result.LastHandledEvent.GlobalVersion() == 0 {
 				result.LastHandledEvent = lastHandledEvent
@@ -154,6 +251,13 @@ func (p *Projection) RunToEnd(ctx context.Context) ProjectionResult {
 			}
 			// hit the end of the event stream
 			if !ran {
+				// store the checkpoint if it's behind the handled events
+				if p.batches > 0 {
+					err := p.saveCheckpoint()
+					if err != nil {
+						result.Error = err
+					}
+				}
 				return result
 			}
 			lastHandledEvent = result.LastHandledEvent
@@ -163,56 +267,161 @@ func (p *Projection) RunToEnd(ctx context.Context) ProjectionResult {
 
 // RunOnce runs the fetch method one time
 func (p *Projection) RunOnce() (bool, ProjectionResult) {
+	return p.runOnce(context.Background())
+}
+
+// runOnce runs the fetch method one time and updates the status. The context stops the wait between retries.
+func (p *Projection) runOnce(ctx context.Context) (bool, ProjectionResult) {
+	start := p.clock().Now()
+	err := p.validate()
+	if err != nil {
+		p.updateStatus(start, p.Position(), err)
+		return false, ProjectionResult{Error: err, Name: p.Name}
+	}
+	// the checkpoint is loaded before the start position is read to not count the restored position as handled events
+	err = p.loadCheckpoint()
+	if err != nil {
+		p.updateStatus(start, p.Position(), err)
+		return false, ProjectionResult{Error: err, Name: p.Name}
+	}
+	position := p.Position()
+	ran, result := p.fetchAndHandle(ctx)
+	p.updateStatus(start, position, result.Error)
+	return ran, result
+}
+
+// validate returns an error if the projection properties can't be combined
+func (p *Projection) validate() error {
+	if p.batchCallbackF != nil && p.Partitions > 1 {
+		return ErrPartitionsBatchProjection
+	}
+	// a loaded checkpoint would not be passed to the fetch func
+	if p.ownPosition && p.Checkpoint != nil {
+		return ErrCheckpointProjection
+	}
+	return nil
+}
+
+// fetchAndHandle fetch events one time and handles them
+func (p *Projection) fetchAndHandle(ctx context.Context) (bool, ProjectionResult) {
 	// ran indicate if there were events to fetch
 	var ran bool
 	var lastHandledEvent Event
 
-	iterator, err := p.fetchF()
+	iterator, err := p.fetchF(core.Version(p.Position()) + 1)
 	if err != nil {
 		return false, ProjectionResult{Error: err, Name: p.Name, LastHandledEvent: lastHandledEvent}
 	}
 	defer iterator.Close()
 
+	if p.batchCallbackF != nil {
+		ran, lastHandledEvent, err = p.runBatch(ctx, iterator)
+	} else if p.Partitions > 1 {
+		ran, lastHandledEvent, err = p.runPartitioned(ctx, iterator)
+	} else {
+		ran, lastHandledEvent, err = p.runSequential(ctx, iterator)
+	}
+	if err != nil {
+		return false, ProjectionResult{Error: err, Name: p.Name, LastHandledEvent: lastHandledEvent}
+	}
+	if ran && p.Checkpoint != nil && !p.CallbackCheckpoint {
+		p.batches++
+		if p.batches >= p.CheckpointEvery {
+			err = p.saveCheckpoint()
+			if err != nil {
+				return false, ProjectionResult{Error: err, Name: p.Name, LastHandledEvent: lastHandledEvent}
+			}
+		}
+	}
+	return ran, ProjectionResult{Error: nil, Name: p.Name, LastHandledEvent: lastHandledEvent}
+}
+
+// runSequential handles the events one at a time in the iterator order
+func (p *Projection) runSequential(ctx context.Context, iterator core.Iterator) (bool, Event, error) {
+	var ran bool
+	var lastHandledEvent Event
+
 	for iterator.Next() {
 		ran = true
 		event, err := iterator.Value()
 		if err != nil {
-			return false, ProjectionResult{Error: err, Name: p.Name, LastHandledEvent: lastHandledEvent}
+			return ran, lastHandledEvent, err
 		}
 
-		// TODO: is only registered events of interest?
-		f, found := p.handler.register.EventRegistered(event)
+		e, found, err := p.decode(event)
+		if err != nil {
+			return ran, lastHandledEvent, err
+		}
 		if !found {
-			if p.Strict {
-				err = fmt.Errorf("event not registered aggregate type: %s, reason: %s, global version: %d, %w", event.AggregateType, event.Reason, event.GlobalVersion, ErrEventNotRegistered)
-				return false, ProjectionResult{Error: err, Name: p.Name, LastHandledEvent: lastHandledEvent}
-			}
+			p.setPosition(event.GlobalVersion)
 			continue
 		}
 
-		data := f()
-		err = p.handler.Encoder.Deserialize(event.Data, &data)
+		err = p.handle(ctx, e)
 		if err != nil {
-			return false, ProjectionResult{Error: err, Name: p.Name, LastHandledEvent: lastHandledEvent}
+			return ran, lastHandledEvent, err
 		}
+		// keep a reference to the last successfully handled event
+		lastHandledEvent = e
+		p.setPosition(event.GlobalVersion)
+	}
+	return ran, lastHandledEvent, nil
+}
 
-		metadata := make(map[string]interface{})
-		if event.Metadata != nil {
-			err = p.handler.Encoder.Deserialize(event.Metadata, &metadata)
-			if err != nil {
-				return false, ProjectionResult{Error: err, Name: p.Name, LastHandledEvent: lastHandledEvent}
-			}
+// decode transforms the event from the event store to an application event. It returns false if the
+// event is not registered and the projection is not strict.
+func (p *Projection) decode(event core.Event) (Event, bool, error) {
+	// TODO: is only registered events of interest?
+	f, found := p.handler.register.EventRegistered(event)
+	if !found {
+		if p.Strict {
+			err := fmt.Errorf("event not registered aggregate type: %s, reason: %s, global version: %d, %w", event.AggregateType, event.Reason, event.GlobalVersion, ErrEventNotRegistered)
+			return Event{}, false, err
 		}
-		e := NewEvent(event, data, metadata)
+		return Event{}, false, nil
+	}
+
+	data := f()
+	err := p.handler.Encoder.Deserialize(event.Data, &data)
+	if err != nil {
+		return Event{}, false, err
+	}
 
-		err = p.callbackF(e)
+	metadata := make(map[string]interface{})
+	if event.Metadata != nil {
+		err = p.handler.Encoder.Deserialize(event.Metadata, &metadata)
 		if err != nil {
-			return false, ProjectionResult{Error: err, Name: p.Name, LastHandledEvent: lastHandledEvent}
+			return Event{}, false, err
 		}
-		// keep a reference to the last successfully handled event
-		lastHandledEvent = e
 	}
-	return ran, ProjectionResult{Error: nil, Name: p.Name, LastHandledEvent: lastHandledEvent}
+	return NewEvent(event, data, metadata), true, nil
+}
+
+// loadCheckpoint sets the projection position from the checkpoint store the first time it's called
+func (p *Projection) loadCheckpoint() error {
+	if p.Checkpoint == nil || p.loaded {
+		return nil
+	}
+	position, err := p.Checkpoint.Get(context.Background(), p.Key())
+	if err != nil && !errors.Is(err, ErrCheckpointNotFound) {
+		return err
+	}
+	p.setPosition(position)
+	p.loaded = true
+	return nil
+}
+
+// saveCheckpoint stores the projection position in the checkpoint store
+func (p *Projection) saveCheckpoint() error {
+	if p.Checkpoint == nil {
+		return nil
+	}
+	err := p.Checkpoint.Save(context.Background(), p.Key(), core.Version(p.Position()))
+	if err != nil {
+		return err
+	}
+	p.batches = 0
+	return nil
 }
 
 // Group runs a group of projections concurrently
@@ -221,6 +430,7 @@ func (ph *ProjectionHandler) Group(projections ...*Projection) *Group {
 		handler:     ph,
 		projections: projections,
 		cancelF:     func() {},
+		running:     make(map[*Projection]groupMember),
 		Pace:        time.Second * 10, // Default pace 10 seconds
 	}
 }
@@ -228,25 +438,84 @@ func (ph *ProjectionHandler) Group(projections ...*Projection) *Group {
 // Start starts all projectinos in the group, an error channel i created on the group to notify
 // if a result containing an error is returned from a projection
 func (g *Group) Start() {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
 	g.ErrChan = make(chan error)
-	ctx, cancel := context.WithCancel(context.Background())
-	g.cancelF = cancel
+	g.ctx, g.cancelF = context.WithCancel(context.Background())
 
-	g.wg.Add(len(g.projections))
 	for _, projection := range g.projections {
-		go func(p *Projection) {
-			defer g.wg.Done()
-			err := p.Run(ctx, g.Pace)
-			if !errors.Is(err, context.Canceled) {
-				g.ErrChan <- err
+		g.start(projection)
+	}
+}
+
+// start runs the projection in a separate go routine, the group lock has to be held by the caller
+func (g *Group) start(p *Projection) {
+	ctx, cancel := context.WithCancel(g.ctx)
+	done := make(chan struct{})
+	g.running[p] = groupMember{cancelF: cancel, done: done}
+
+	// set the projection as running before the start returns to make it possible to trigger it directly
+	claimed := p.running.CompareAndSwap(false, true)
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		defer close(done)
+		g.supervise(ctx, p, claimed)
+	}()
+}
+
+// Add adds projections to the group, they are started directly if the group is started
+func (g *Group) Add(projections ...*Projection) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	for _, p := range projections {
+		g.projections = append(g.projections, p)
+		if g.ctx != nil {
+			g.start(p)
+		}
+	}
+}
+
+// Remove stops the projections if they are running and removes them from the group.
+// It returns when the projections has stopped.
+func (g *Group) Remove(projections ...*Projection) {
+	members := make([]groupMember, 0)
+	g.lock.Lock()
+	for _, p := range projections {
+		if member, running := g.running[p]; running {
+			members = append(members, member)
+			delete(g.running, p)
+		}
+		for i, projection := range g.projections {
+			if projection == p {
+				g.projections = append(g.projections[:i], g.projections[i+1:]...)
+				break
 			}
-		}(projection)
+		}
+	}
+	g.lock.Unlock()
+
+	for _, member := range members {
+		member.cancelF()
+		<-member.done
 	}
 }
 
+// members returns a copy of the projections in the group
+func (g *Group) members() []*Projection {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	projections := make([]*Projection, len(g.projections))
+	copy(projections, g.projections)
+	return projections
+}
+
 // TriggerAsync force all projections to run not waiting for them to finish
 func (g *Group) TriggerAsync() {
-	for _, projection := range g.projections {
+	for _, projection := range g.members() {
 		projection.TriggerAsync()
 	}
 }
@@ -254,7 +523,7 @@ func (g *Group) TriggerAsync() {
 // TriggerSync force all projections to run and wait for them to finish
 func (g *Group) TriggerSync() {
 	wg := sync.WaitGroup{}
-	for _, projection := range g.projections {
+	for _, projection := range g.members() {
 		wg.Add(1)
 		go func(p *Projection) {
 			p.TriggerSync()
@@ -266,10 +535,15 @@ func (g *Group) TriggerSync() {
 
 // Stop halts all projections in the group
 func (g *Group) Stop() {
+	g.lock.Lock()
 	if g.ErrChan == nil {
+		g.lock.Unlock()
 		return
 	}
 	g.cancelF()
+	g.ctx = nil
+	g.running = make(map[*Projection]groupMember)
+	g.lock.Unlock()
 
 	// return when all projections has stopped
 	g.wg.Wait()
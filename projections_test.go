@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/hallgren/eventsourcing"
+	checkpoint "github.com/hallgren/eventsourcing/checkpointstore/memory"
 	"github.com/hallgren/eventsourcing/core"
 	"github.com/hallgren/eventsourcing/eventstore/memory"
 )
@@ -471,3 +472,259 @@ func TestKeepStartPosition(t *testing.T) {
 		t.Fatalf("expected counter to be 10 was %d", counter)
 	}
 }
+
+func TestCheckpoint(t *testing.T) {
+	// setup
+	es := memory.Create()
+	register := eventsourcing.NewRegister()
+	register.Register(&Person{})
+	checkpoints := checkpoint.Create()
+
+	err := createPersonEvent(es, "kalle", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	counter := 0
+	callbackF := func(event eventsourcing.Event) error {
+		counter++
+		return nil
+	}
+
+	p := eventsourcing.NewProjectionHandler(register, eventsourcing.EncoderJSON{})
+	r := p.ProjectionFrom(es.AllFrom(2), callbackF)
+	r.Name = "person"
+	r.Checkpoint = checkpoints
+
+	_, err = p.Race(true, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	position, err := checkpoints.Get(context.Background(), "person")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if position != 6 {
+		t.Fatalf("expected checkpoint to be 6 was %d", position)
+	}
+
+	err = createPersonEvent(es, "anka", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a new projection with the same name should continue from the checkpoint
+	r = p.ProjectionFrom(es.AllFrom(2), callbackF)
+	r.Name = "person"
+	r.Checkpoint = checkpoints
+
+	_, err = p.Race(true, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Born 2 + AgedOneYear 5 + 2 = 9
+	if counter != 9 {
+		t.Fatalf("expected counter to be 9 was %d", counter)
+	}
+	if r.Position() != 9 {
+		t.Fatalf("expected position to be 9 was %d", r.Position())
+	}
+}
+
+func TestCheckpointEvery(t *testing.T) {
+	// setup
+	es := memory.Create()
+	register := eventsourcing.NewRegister()
+	register.Register(&Person{})
+	checkpoints := checkpoint.Create()
+
+	err := createPersonEvent(es, "kalle", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := eventsourcing.NewProjectionHandler(register, eventsourcing.EncoderJSON{})
+	r := p.ProjectionFrom(es.AllFrom(1), func(event eventsourcing.Event) error { return nil })
+	r.Name = "person"
+	r.Checkpoint = checkpoints
+	r.CheckpointEvery = 4
+
+	for i := 0; i < 3; i++ {
+		r.RunOnce()
+	}
+	// no checkpoint stored after three batches
+	_, err = checkpoints.Get(context.Background(), "person")
+	if !errors.Is(err, eventsourcing.ErrCheckpointNotFound) {
+		t.Fatalf("expected ErrCheckpointNotFound got %v", err)
+	}
+
+	r.RunOnce()
+	position, err := checkpoints.Get(context.Background(), "person")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if position != 4 {
+		t.Fatalf("expected checkpoint to be 4 was %d", position)
+	}
+
+	// running to the end stores the checkpoint even if it's not time to store it
+	result := r.RunToEnd(context.Background())
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	position, err = checkpoints.Get(context.Background(), "person")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if position != 6 {
+		t.Fatalf("expected checkpoint to be 6 was %d", position)
+	}
+}
+
+func TestPartitions(t *testing.T) {
+	// setup
+	es := memory.Create()
+	register := eventsourcing.NewRegister()
+	register.Register(&Person{})
+
+	names := []string{"kalle", "anka", "pelle", "stina"}
+	for _, name := range names {
+		err := createPersonEvent(es, name, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var lock sync.Mutex
+	versions := make(map[string][]eventsourcing.Version)
+	p := eventsourcing.NewProjectionHandler(register, eventsourcing.EncoderJSON{})
+	proj := p.Projection(es.All(0, 20), func(event eventsourcing.Event) error {
+		lock.Lock()
+		defer lock.Unlock()
+		versions[event.AggregateID()] = append(versions[event.AggregateID()], event.Version())
+		return nil
+	})
+	proj.Partitions = 3
+
+	result := proj.RunToEnd(context.Background())
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+
+	if len(versions) != len(names) {
+		t.Fatalf("expected events from %d aggregates got %d", len(names), len(versions))
+	}
+	// the events within an aggregate should be handled in order
+	for id, v := range versions {
+		if len(v) != 11 {
+			t.Fatalf("expected 11 events on aggregate %s got %d", id, len(v))
+		}
+		for i, version := range v {
+			if version != eventsourcing.Version(i+1) {
+				t.Fatalf("events handled out of order on aggregate %s %v", id, v)
+			}
+		}
+	}
+	if proj.Position() != 44 {
+		t.Fatalf("expected position 44 got %d", proj.Position())
+	}
+}
+
+func TestPartitionsBatchProjection(t *testing.T) {
+	es := memory.Create()
+	register := eventsourcing.NewRegister()
+	register.Register(&Person{})
+
+	err := createPersonEvent(es, "kalle", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handled := 0
+	p := eventsourcing.NewProjectionHandler(register, eventsourcing.EncoderJSON{})
+	proj := p.BatchProjection(es.AllFrom(10), func(batch eventsourcing.Batch) error {
+		handled++
+		return nil
+	})
+	proj.Partitions = 2
+
+	_, result := proj.RunOnce()
+	if !errors.Is(result.Error, eventsourcing.ErrPartitionsBatchProjection) {
+		t.Fatalf("expected ErrPartitionsBatchProjection got %v", result.Error)
+	}
+	if handled != 0 || proj.Position() != 0 {
+		t.Fatalf("expected no handled batches got %d handled and position %d", handled, proj.Position())
+	}
+}
+
+func TestCheckpointProjection(t *testing.T) {
+	es := memory.Create()
+	register := eventsourcing.NewRegister()
+	register.Register(&Person{})
+	checkpoints := checkpoint.Create()
+
+	err := createPersonEvent(es, "kalle", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = checkpoints.Save(context.Background(), "person", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handled := 0
+	p := eventsourcing.NewProjectionHandler(register, eventsourcing.EncoderJSON{})
+	proj := p.Projection(es.All(0, 10), func(event eventsourcing.Event) error {
+		handled++
+		return nil
+	})
+	proj.Name = "person"
+	proj.Checkpoint = checkpoints
+
+	_, result := proj.RunOnce()
+	if !errors.Is(result.Error, eventsourcing.ErrCheckpointProjection) {
+		t.Fatalf("expected ErrCheckpointProjection got %v", result.Error)
+	}
+	if handled != 0 || proj.Position() != 0 {
+		t.Fatalf("expected no handled events got %d handled and position %d", handled, proj.Position())
+	}
+}
+
+func TestPartitionsPositionOnError(t *testing.T) {
+	// setup
+	es := memory.Create()
+	register := eventsourcing.NewRegister()
+	register.Register(&Person{})
+
+	for _, name := range []string{"kalle", "anka"} {
+		err := createPersonEvent(es, name, 4)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ErrApplication := errors.New("application error")
+	p := eventsourcing.NewProjectionHandler(register, eventsourcing.EncoderJSON{})
+	proj := p.ProjectionFrom(es.AllFrom(10), func(event eventsourcing.Event) error {
+		// fail on the third event of the first aggregate
+		if event.GlobalVersion() == 3 {
+			return ErrApplication
+		}
+		return nil
+	})
+	proj.Partitions = 2
+
+	_, result := proj.RunOnce()
+	if !errors.Is(result.Error, ErrApplication) {
+		t.Fatalf("expected application error got %v", result.Error)
+	}
+	// the position should stop before the failing event even if later events are handled
+	if proj.Position() != 2 {
+		t.Fatalf("expected position 2 got %d", proj.Position())
+	}
+	if result.LastHandledEvent.GlobalVersion() != 2 {
+		t.Fatalf("expected last handled event 2 got %d", result.LastHandledEvent.GlobalVersion())
+	}
+}
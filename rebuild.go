@@ -0,0 +1,109 @@
+package eventsourcing
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrRebuildNotCaughtUp is returned when switching to a rebuilt projection that has not caught up with the live projection
+	ErrRebuildNotCaughtUp = errors.New("rebuilt projection has not caught up with the live projection")
+
+	// ErrRebuildSameVersion is returned when the rebuilt projection has the same name and version as the live projection
+	ErrRebuildSameVersion = errors.New("rebuilt projection has the same name and version as the live projection")
+)
+
+// Rebuild runs a new version of a projection next to the live version until it's caught up and readers can be switched over
+type Rebuild struct {
+	group *Group
+	live  *Projection
+	next  *Projection
+}
+
+// Rebuild adds the next version of the live projection to the group. It runs from its own checkpoint (global version 0 if
+// it has none) in parallel with the live projection, that is kept in the group until Switch is called.
+func (g *Group) Rebuild(live, next *Projection) (*Rebuild, error) {
+	if live.Key() == next.Key() {
+		return nil, ErrRebuildSameVersion
+	}
+	g.Add(next)
+	return &Rebuild{
+		group: g,
+		live:  live,
+		next:  next,
+	}, nil
+}
+
+// Progress returns the position of the rebuilt projection and the position of the live projection it's catching up to
+func (r *Rebuild) Progress() (position Version, target Version) {
+	return r.next.Position(), r.live.Position()
+}
+
+// CaughtUp returns true when the rebuilt projection has reached the position of the live projection
+func (r *Rebuild) CaughtUp() bool {
+	position, target := r.Progress()
+	return position >= target
+}
+
+// Wait blocks until the rebuilt projection has caught up or the context is cancelled
+func (r *Rebuild) Wait(ctx context.Context) error {
+	for !r.CaughtUp() {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Millisecond * 10):
+		}
+	}
+	return nil
+}
+
+// Switch calls the switch func that moves the readers over to the rebuilt read-model and then stops and
+// removes the live projection from the group. The switch func is the place to make the read-model switch
+// atomic, e.g. swapping a pointer or renaming a database view. If the switch func returns an error the live
+// projection is kept.
+//
+// The checkpoint and dead letters of the retired version are deleted after it's removed from the group. An error
+// from the cleanup is returned after the switch is done.
+func (r *Rebuild) Switch(switchF func() error) error {
+	if !r.CaughtUp() {
+		return ErrRebuildNotCaughtUp
+	}
+	if switchF != nil {
+		err := switchF()
+		if err != nil {
+			return err
+		}
+	}
+	r.group.Remove(r.live)
+	return r.live.deleteState(context.Background())
+}
+
+// Abort stops and removes the rebuilt projection from the group, keeping the live projection
+func (r *Rebuild) Abort() {
+	r.group.Remove(r.next)
+}
+
+// deleteState deletes the checkpoint and the parked events of the projection
+func (p *Projection) deleteState(ctx context.Context) error {
+	if p.Checkpoint != nil {
+		err := p.Checkpoint.Delete(ctx, p.Key())
+		if err != nil && !errors.Is(err, ErrCheckpointNotFound) {
+			return err
+		}
+	}
+	if p.DeadLetters == nil {
+		return nil
+	}
+	deadLetters, err := p.DeadLetters.List(ctx, p.Key())
+	if err != nil {
+		return err
+	}
+	for _, d := range deadLetters {
+		err = p.DeadLetters.Remove(ctx, p.Key(), d.Event.GlobalVersion)
+		if err != nil && !errors.Is(err, ErrDeadLetterNotFound) {
+			return err
+		}
+	}
+	return nil
+}
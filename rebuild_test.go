@@ -0,0 +1,257 @@
+package eventsourcing_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hallgren/eventsourcing"
+	checkpoint "github.com/hallgren/eventsourcing/checkpointstore/memory"
+	deadletter "github.com/hallgren/eventsourcing/deadletterstore/memory"
+	"github.com/hallgren/eventsourcing/eventstore/memory"
+)
+
+// readModel is a read-model that is built by a projection
+type readModel struct {
+	lock  sync.Mutex
+	names []string
+}
+
+func (r *readModel) add(name string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.names = append(r.names, name)
+}
+
+func (r *readModel) count() int {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return len(r.names)
+}
+
+func TestRebuild(t *testing.T) {
+	// setup
+	es := memory.Create()
+	register := eventsourcing.NewRegister()
+	register.Register(&Person{})
+	checkpoints := checkpoint.Create()
+
+	for _, name := range []string{"kalle", "anka"} {
+		err := createPersonEvent(es, name, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	projection := func(rm *readModel, version int) *eventsourcing.Projection {
+		p := eventsourcing.NewProjectionHandler(register, eventsourcing.EncoderJSON{})
+		proj := p.ProjectionFrom(es.AllFrom(1), func(event eventsourcing.Event) error {
+			switch e := event.Data().(type) {
+			case *Born:
+				rm.add(e.Name)
+			}
+			return nil
+		})
+		proj.Name = "names"
+		proj.Version = version
+		proj.Checkpoint = checkpoints
+		return proj
+	}
+
+	// the readers use the active read-model
+	var active atomic.Pointer[readModel]
+	v1 := &readModel{}
+	active.Store(v1)
+
+	live := projection(v1, 1)
+	g := eventsourcing.NewProjectionHandler(register, eventsourcing.EncoderJSON{}).Group(live)
+	g.Pace = time.Millisecond * 10
+	g.Start()
+	defer g.Stop()
+	g.TriggerSync()
+
+	if v1.count() != 2 {
+		t.Fatalf("expected two names in the live read-model got %d", v1.count())
+	}
+
+	_, err := g.Rebuild(live, projection(&readModel{}, 1))
+	if !errors.Is(err, eventsourcing.ErrRebuildSameVersion) {
+		t.Fatalf("expected ErrRebuildSameVersion got %v", err)
+	}
+
+	v2 := &readModel{}
+	next := projection(v2, 2)
+	rebuild, err := g.Rebuild(live, next)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	err = rebuild.Wait(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	position, target := rebuild.Progress()
+	if position < target {
+		t.Fatalf("expected position %d to have reached target %d", position, target)
+	}
+
+	err = rebuild.Switch(func() error {
+		active.Store(v2)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if active.Load().count() != 2 {
+		t.Fatalf("expected two names in the active read-model got %d", active.Load().count())
+	}
+
+	// only the rebuilt projection is running after the switch
+	err = createPersonEvent(es, "pelle", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.TriggerSync()
+	if v2.count() != 3 {
+		t.Fatalf("expected three names in the rebuilt read-model got %d", v2.count())
+	}
+	if v1.count() != 2 {
+		t.Fatalf("expected the retired read-model to be untouched got %d names", v1.count())
+	}
+
+	// the checkpoints of the two versions are stored separately and the retired version is deleted by the switch
+	_, err = checkpoints.Get(context.Background(), "names_v1")
+	if !errors.Is(err, eventsourcing.ErrCheckpointNotFound) {
+		t.Fatalf("expected the checkpoint of the retired version to be deleted got %v", err)
+	}
+	p2, err := checkpoints.Get(context.Background(), "names_v2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p2 != 7 {
+		t.Fatalf("expected checkpoint 7 got %d", p2)
+	}
+}
+
+func TestRebuildSwitchDeletesDeadLetters(t *testing.T) {
+	// setup
+	es := memory.Create()
+	register := eventsourcing.NewRegister()
+	register.Register(&Person{})
+	deadLetters := deadletter.Create()
+
+	err := createPersonEvent(es, "kalle", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := eventsourcing.NewProjectionHandler(register, eventsourcing.EncoderJSON{})
+	// the live version fails on the second event that is parked
+	live := p.ProjectionFrom(es.AllFrom(10), func(event eventsourcing.Event) error {
+		if event.GlobalVersion() == 2 {
+			return errors.New("could not handle event")
+		}
+		return nil
+	})
+	live.Name = "names"
+	live.DeadLetters = deadLetters
+	live.RunToEnd(context.Background())
+
+	next := p.ProjectionFrom(es.AllFrom(10), func(event eventsourcing.Event) error { return nil })
+	next.Name = "names"
+	next.Version = 2
+	next.DeadLetters = deadLetters
+	next.RunToEnd(context.Background())
+
+	rebuild, err := p.Group(live).Rebuild(live, next)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = rebuild.Switch(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parked, err := live.ParkedEvents(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parked) != 0 {
+		t.Fatalf("expected the parked events of the retired version to be deleted got %d", len(parked))
+	}
+}
+
+func TestRebuildWaitUntil(t *testing.T) {
+	// setup
+	es := memory.Create()
+	register := eventsourcing.NewRegister()
+	register.Register(&Person{})
+
+	err := createPersonEvent(es, "kalle", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := eventsourcing.NewProjectionHandler(register, eventsourcing.EncoderJSON{})
+	live := p.ProjectionFrom(es.AllFrom(10), func(event eventsourcing.Event) error { return nil })
+	live.Name = "names"
+	live.RunToEnd(context.Background())
+
+	// the rebuild has the same name and has not handled any events
+	next := p.ProjectionFrom(es.AllFrom(10), func(event eventsourcing.Event) error { return nil })
+	next.Name = "names"
+	next.Version = 2
+
+	g := p.Group(live)
+	_, err = g.Rebuild(live, next)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*100)
+	defer cancel()
+	// the names are matched on the key, the name with the version when it's set
+	err = g.WaitUntil(ctx, 3, "names")
+	if err != nil {
+		t.Fatalf("expected the live projection to have handled the events got %v", err)
+	}
+	err = g.WaitUntil(ctx, 3, "names_v2")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the rebuild to not have handled the events got %v", err)
+	}
+}
+
+func TestRebuildNotCaughtUp(t *testing.T) {
+	// setup
+	es := memory.Create()
+	register := eventsourcing.NewRegister()
+	register.Register(&Person{})
+
+	err := createPersonEvent(es, "kalle", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := eventsourcing.NewProjectionHandler(register, eventsourcing.EncoderJSON{})
+	live := p.ProjectionFrom(es.AllFrom(10), func(event eventsourcing.Event) error { return nil })
+	live.RunToEnd(context.Background())
+
+	next := p.ProjectionFrom(es.AllFrom(10), func(event eventsourcing.Event) error { return nil })
+	next.Version = 2
+
+	// the group is not started
+	g := p.Group(live)
+	rebuild, err := g.Rebuild(live, next)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = rebuild.Switch(nil)
+	if !errors.Is(err, eventsourcing.ErrRebuildNotCaughtUp) {
+		t.Fatalf("expected ErrRebuildNotCaughtUp got %v", err)
+	}
+	rebuild.Abort()
+}
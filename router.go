@@ -0,0 +1,59 @@
+package eventsourcing
+
+import (
+	"errors"
+	"reflect"
+)
+
+// ErrEventNotHandled is returned from the router when there is no handler for the event data type
+var ErrEventNotHandled = errors.New("event not handled")
+
+// Router routes events to typed handlers based on the event data type
+type Router struct {
+	handlers map[reflect.Type]func(e Event) error
+}
+
+// NewRouter factory function
+func NewRouter() *Router {
+	return &Router{
+		handlers: make(map[reflect.Type]func(e Event) error),
+	}
+}
+
+// On register a handler for events with data of type T. A previously registered handler for the same type is replaced.
+func On[T any](r *Router, f func(e Event, data *T) error) {
+	r.handlers[reflect.TypeOf((*T)(nil))] = func(e Event) error {
+		return f(e, e.Data().(*T))
+	}
+}
+
+// Handle calls the handler registered for the event data type. It has the signature of the projection callback func.
+// If there is no handler it returns ErrEventNotHandled, that a projection treats the same way as an event not found in the
+// register depending on the projection Strict property.
+func (r *Router) Handle(e Event) error {
+	f, ok := r.handlers[reflect.TypeOf(e.Data())]
+	if !ok {
+		return ErrEventNotHandled
+	}
+	return f(e)
+}
+
+// Subscriber returns a func to be used in the event stream subscriptions. Errors from the handlers are passed to errF,
+// events without a handler are ignored.
+func (r *Router) Subscriber(errF func(e Event, err error)) func(e Event) {
+	return func(e Event) {
+		err := r.Handle(e)
+		if err != nil && !errors.Is(err, ErrEventNotHandled) && errF != nil {
+			errF(e, err)
+		}
+	}
+}
+
+// Events returns instances of the handled event types, to be used in the event stream Event subscription
+func (r *Router) Events() []interface{} {
+	events := make([]interface{}, 0, len(r.handlers))
+	for t := range r.handlers {
+		events = append(events, reflect.New(t.Elem()).Interface())
+	}
+	return events
+}
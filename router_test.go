@@ -0,0 +1,111 @@
+package eventsourcing_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/eventstore/memory"
+)
+
+func TestRouterProjection(t *testing.T) {
+	// setup
+	es := memory.Create()
+	register := eventsourcing.NewRegister()
+	register.Register(&Person{})
+
+	err := createPersonEvent(es, "kalle", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	projectedName := ""
+	router := eventsourcing.NewRouter()
+	eventsourcing.On(router, func(e eventsourcing.Event, born *Born) error {
+		projectedName = born.Name
+		return nil
+	})
+
+	p := eventsourcing.NewProjectionHandler(register, eventsourcing.EncoderJSON{})
+	proj := p.Projection(es.All(0, 10), router.Handle)
+
+	// the AgedOneYear event is not handled by the router
+	_, result := proj.RunOnce()
+	if !errors.Is(result.Error, eventsourcing.ErrEventNotHandled) {
+		t.Fatalf("expected ErrEventNotHandled got %v", result.Error)
+	}
+
+	proj = p.Projection(es.All(0, 10), router.Handle)
+	proj.Strict = false
+
+	_, result = proj.RunOnce()
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	if projectedName != "kalle" {
+		t.Fatalf("expected projected name kalle got %q", projectedName)
+	}
+	if proj.Position() != 3 {
+		t.Fatalf("expected position 3 got %d", proj.Position())
+	}
+}
+
+func TestRouterHandlerError(t *testing.T) {
+	ErrApplication := errors.New("application error")
+	router := eventsourcing.NewRouter()
+	eventsourcing.On(router, func(e eventsourcing.Event, born *Born) error {
+		return ErrApplication
+	})
+
+	person, err := CreatePerson("kalle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = router.Handle(person.Events()[0])
+	if !errors.Is(err, ErrApplication) {
+		t.Fatalf("expected application error got %v", err)
+	}
+}
+
+func TestRouterSubscriber(t *testing.T) {
+	repo := eventsourcing.NewEventRepository(memory.Create())
+	repo.Register(&Person{})
+
+	names := make([]string, 0)
+	aged := 0
+	router := eventsourcing.NewRouter()
+	eventsourcing.On(router, func(e eventsourcing.Event, born *Born) error {
+		names = append(names, born.Name)
+		return nil
+	})
+	eventsourcing.On(router, func(e eventsourcing.Event, _ *AgedOneYear) error {
+		aged++
+		return errors.New("subscriber error")
+	})
+
+	errs := 0
+	s := repo.Subscribers().Event(router.Subscriber(func(e eventsourcing.Event, err error) {
+		errs++
+	}), router.Events()...)
+	defer s.Close()
+
+	person, err := CreatePerson("kalle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	person.GrowOlder()
+	err = repo.Save(person)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(names) != 1 || names[0] != "kalle" {
+		t.Fatalf("expected the Born event to be handled got %v", names)
+	}
+	if aged != 1 {
+		t.Fatalf("expected the AgedOneYear event to be handled once got %d", aged)
+	}
+	if errs != 1 {
+		t.Fatalf("expected one error got %d", errs)
+	}
+}
@@ -0,0 +1,154 @@
+package eventsourcing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// SagaPositionKey is the metadata key on saga events that holds the global version of the event that was
+// handled by the saga
+const SagaPositionKey = "saga_position"
+
+// CorrelationFunc returns the id of the saga instance that the event belongs to and false if the event
+// is not related to a saga instance
+type CorrelationFunc func(e Event) (string, bool)
+
+// CorrelateByAggregateID correlates events to saga instances with the same id as the event aggregate
+func CorrelateByAggregateID() CorrelationFunc {
+	return func(e Event) (string, bool) {
+		return e.AggregateID(), true
+	}
+}
+
+// CorrelateByMetadata correlates events to saga instances by the string value of the metadata key
+func CorrelateByMetadata(key string) CorrelationFunc {
+	return func(e Event) (string, bool) {
+		id, ok := e.Metadata()[key].(string)
+		if !ok || id == "" {
+			return "", false
+		}
+		return id, true
+	}
+}
+
+// SagaCommand is a command issued by a saga instance. The ID is the same each time the saga handles the
+// same event, which makes it possible for the receiver to skip commands it has already handled.
+type SagaCommand struct {
+	ID      string      // ID is built from the saga id, the global version of the handled event and the command index
+	Saga    string      // Saga is the name of the saga
+	SagaID  string      // SagaID is the id of the saga instance
+	Command interface{} // Command is the command returned from the saga handler
+}
+
+// Saga reacts to events by issuing commands. Each saga instance is an aggregate stored in the event
+// repository and is correlated to the events via the Correlate func.
+//
+// For each event the saga instance is loaded, the handler tracks the state changes on it and returns the
+// commands to dispatch. The commands are dispatched before the saga instance is saved. If the process crash
+// in between, the event is handled again and the commands are dispatched with the same ids. Events already
+// handled by a saga instance are skipped.
+type Saga[S aggregate] struct {
+	name      string
+	repo      *EventRepository
+	newF      func() S
+	handleF   func(saga S, e Event) ([]interface{}, error)
+	dispatchF func(ctx context.Context, cmd SagaCommand) error
+	Correlate CorrelationFunc // Correlate maps events to saga instances. Default correlate by aggregate id
+}
+
+// NewSaga creates a saga where the saga instances are stored in the repository. The saga aggregate is
+// registered in the repository.
+func NewSaga[S aggregate](repo *EventRepository, name string, newF func() S, handleF func(saga S, e Event) ([]interface{}, error), dispatchF func(ctx context.Context, cmd SagaCommand) error) *Saga[S] {
+	repo.Register(newF())
+	return &Saga[S]{
+		name:      name,
+		repo:      repo,
+		newF:      newF,
+		handleF:   handleF,
+		dispatchF: dispatchF,
+		Correlate: CorrelateByAggregateID(),
+	}
+}
+
+// Projection creates the projection that feeds the saga with events. Store the checkpoint of the
+// projection in a checkpoint store to make the saga resume from its position after a restart.
+func (s *Saga[S]) Projection(fetchF fetchFromFunc) *Projection {
+	p := s.repo.Projections.ProjectionFrom(fetchF, s.Handle)
+	p.Name = s.name
+	return p
+}
+
+// Handle handles the event in the correlated saga instance
+func (s *Saga[S]) Handle(e Event) error {
+	ctx := context.Background()
+	saga := s.newF()
+	// the saga events is not handled by the saga itself
+	if e.AggregateType() == aggregateType(saga) {
+		return nil
+	}
+	id, ok := s.Correlate(e)
+	if !ok {
+		return nil
+	}
+
+	position, err := s.load(ctx, id, saga)
+	if errors.Is(err, ErrAggregateNotFound) {
+		err = saga.Root().SetID(id)
+	}
+	if err != nil {
+		return err
+	}
+	if position >= e.GlobalVersion() {
+		return nil
+	}
+
+	commands, err := s.handleF(saga, e)
+	if err != nil {
+		return err
+	}
+	for i, command := range commands {
+		err = s.dispatchF(ctx, SagaCommand{
+			ID:      fmt.Sprintf("%s-%s-%d-%d", s.name, id, e.GlobalVersion(), i),
+			Saga:    s.name,
+			SagaID:  id,
+			Command: command,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	root := saga.Root()
+	if !root.UnsavedEvents() {
+		return nil
+	}
+	// mark the saga events with the handled event
+	for i := range root.aggregateEvents {
+		if root.aggregateEvents[i].metadata == nil {
+			root.aggregateEvents[i].metadata = make(map[string]interface{})
+		}
+		root.aggregateEvents[i].metadata[SagaPositionKey] = strconv.FormatUint(uint64(e.GlobalVersion()), 10)
+	}
+	return s.repo.Save(saga)
+}
+
+// load builds the saga instance and returns the global version of the last event handled by it
+func (s *Saga[S]) load(ctx context.Context, id string, saga S) (Version, error) {
+	var position Version
+	var err error
+	getErr := s.repo.get(ctx, id, saga, func(e Event) {
+		value, ok := e.Metadata()[SagaPositionKey].(string)
+		if !ok {
+			return
+		}
+		var v uint64
+		v, err = strconv.ParseUint(value, 10, 64)
+		position = Version(v)
+	})
+	if getErr != nil {
+		return 0, getErr
+	}
+	return position, err
+}
@@ -0,0 +1,205 @@
+package eventsourcing_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hallgren/eventsourcing"
+	checkpoint "github.com/hallgren/eventsourcing/checkpointstore/memory"
+	"github.com/hallgren/eventsourcing/eventstore/memory"
+)
+
+// Birthday is a saga that sends a card when a person turns two
+type Birthday struct {
+	eventsourcing.AggregateRoot
+	Name string
+	Age  int
+}
+
+type BirthdayNameSet struct {
+	Name string
+}
+
+type BirthdayAged struct{}
+
+type SendCard struct {
+	Name string
+}
+
+func (b *Birthday) Register(f eventsourcing.RegisterFunc) {
+	f(&BirthdayNameSet{}, &BirthdayAged{})
+}
+
+func (b *Birthday) Transition(event eventsourcing.Event) {
+	switch e := event.Data().(type) {
+	case *BirthdayNameSet:
+		b.Name = e.Name
+	case *BirthdayAged:
+		b.Age++
+	}
+}
+
+func birthdayHandler(b *Birthday, event eventsourcing.Event) ([]interface{}, error) {
+	switch e := event.Data().(type) {
+	case *Born:
+		b.TrackChange(b, &BirthdayNameSet{Name: e.Name})
+	case *AgedOneYear:
+		b.TrackChange(b, &BirthdayAged{})
+		if b.Age == 2 {
+			return []interface{}{SendCard{Name: b.Name}}, nil
+		}
+	}
+	return nil, nil
+}
+
+func TestSaga(t *testing.T) {
+	es := memory.Create()
+	repo := eventsourcing.NewEventRepository(es)
+	repo.Register(&Person{})
+
+	person, err := CreatePerson("kalle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	person.GrowOlder()
+	person.GrowOlder()
+	err = repo.Save(person)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	commands := make([]eventsourcing.SagaCommand, 0)
+	saga := eventsourcing.NewSaga(repo, "birthday", func() *Birthday { return &Birthday{} }, birthdayHandler, func(ctx context.Context, cmd eventsourcing.SagaCommand) error {
+		commands = append(commands, cmd)
+		return nil
+	})
+	checkpoints := checkpoint.Create()
+	p := saga.Projection(es.AllFrom(1))
+	p.Checkpoint = checkpoints
+
+	result := p.RunToEnd(context.Background())
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	if len(commands) != 1 {
+		t.Fatalf("expected one command got %d", len(commands))
+	}
+	if commands[0].Command.(SendCard).Name != "kalle" || commands[0].SagaID != person.ID() || commands[0].Saga != "birthday" {
+		t.Fatalf("wrong command %v", commands[0])
+	}
+
+	b := &Birthday{}
+	err = repo.Get(person.ID(), b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Name != "kalle" || b.Age != 2 {
+		t.Fatalf("wrong saga state %v", b)
+	}
+	position, err := checkpoints.Get(context.Background(), "birthday")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the saga events are stored in the same event store
+	if position != 6 {
+		t.Fatalf("expected checkpoint 6 got %d", position)
+	}
+
+	// events already handled by the saga instance are skipped, e.g. when the checkpoint is lost
+	result = saga.Projection(es.AllFrom(1)).RunToEnd(context.Background())
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	if len(commands) != 1 {
+		t.Fatalf("expected no new commands got %d", len(commands)-1)
+	}
+}
+
+func TestSagaDispatchError(t *testing.T) {
+	es := memory.Create()
+	repo := eventsourcing.NewEventRepository(es)
+	repo.Register(&Person{})
+
+	person, err := CreatePerson("kalle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	person.GrowOlder()
+	person.GrowOlder()
+	err = repo.Save(person)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ErrDispatch := errors.New("dispatch error")
+	fail := true
+	ids := make([]string, 0)
+	saga := eventsourcing.NewSaga(repo, "birthday", func() *Birthday { return &Birthday{} }, birthdayHandler, func(ctx context.Context, cmd eventsourcing.SagaCommand) error {
+		ids = append(ids, cmd.ID)
+		if fail {
+			return ErrDispatch
+		}
+		return nil
+	})
+	p := saga.Projection(es.AllFrom(1))
+
+	result := p.RunToEnd(context.Background())
+	if !errors.Is(result.Error, ErrDispatch) {
+		t.Fatalf("expected dispatch error got %v", result.Error)
+	}
+
+	// the event is handled again and the command is dispatched with the same id
+	fail = false
+	result = p.RunToEnd(context.Background())
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	if len(ids) != 2 || ids[0] != ids[1] {
+		t.Fatalf("expected the command to be dispatched twice with the same id got %v", ids)
+	}
+
+	b := &Birthday{}
+	err = repo.Get(person.ID(), b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Age != 2 {
+		t.Fatalf("expected saga age 2 got %d", b.Age)
+	}
+}
+
+func TestCorrelateByMetadata(t *testing.T) {
+	es := memory.Create()
+	repo := eventsourcing.NewEventRepository(es)
+	repo.Register(&Person{})
+
+	person, err := CreatePerson("kalle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// only the AgedOneYear event has the foo metadata
+	person.GrowOlder()
+	err = repo.Save(person)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	saga := eventsourcing.NewSaga(repo, "birthday", func() *Birthday { return &Birthday{} }, birthdayHandler, func(ctx context.Context, cmd eventsourcing.SagaCommand) error {
+		return nil
+	})
+	saga.Correlate = eventsourcing.CorrelateByMetadata("foo")
+	result := saga.Projection(es.AllFrom(1)).RunToEnd(context.Background())
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+
+	b := &Birthday{}
+	err = repo.Get("bar", b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Age != 1 || b.Name != "" {
+		t.Fatalf("expected only the aged event in the saga got %v", b)
+	}
+}
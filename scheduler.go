@@ -0,0 +1,140 @@
+package eventsourcing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// ErrScheduledTypeNotHandled is returned when scheduling or firing an item without a handler for its data type
+var ErrScheduledTypeNotHandled = errors.New("scheduled type not handled")
+
+// Scheduler persists events or commands to be fired at a later time, e.g. to expire a reservation
+// if it's not paid within 15 minutes.
+//
+// A due item is removed from the schedule store after its handler returns without error. If the process crash
+// in between the item will be fired again, i.e. at-least-once delivery.
+type Scheduler struct {
+	store     ScheduleStore
+	handlers  map[string]scheduledHandler
+	Encoder   encoder
+	Clock     Clock         // Clock is used to get the current time and for the pace. Default the global clock set by SetClock
+	Pace      time.Duration // Pace is the time to wait when there are no due items
+	BatchSize int           // BatchSize is the max number of due items fetched in one run
+}
+
+// scheduledHandler decodes the data and calls the handler
+type scheduledHandler func(ctx context.Context, id string, data []byte) error
+
+// NewScheduler factory function
+func NewScheduler(store ScheduleStore) *Scheduler {
+	return &Scheduler{
+		store:     store,
+		handlers:  make(map[string]scheduledHandler),
+		Encoder:   EncoderJSON{},
+		Pace:      time.Second * 1, // Default pace 1 second
+		BatchSize: 100,             // Default batch size 100 items
+	}
+}
+
+// OnScheduled register the handler that is called when items with data of type T are due. A previously registered
+// handler for the same type is replaced.
+func OnScheduled[T any](s *Scheduler, f func(ctx context.Context, id string, data *T) error) {
+	s.handlers[scheduledType(reflect.TypeOf((*T)(nil)))] = func(ctx context.Context, id string, data []byte) error {
+		v := new(T)
+		err := s.Encoder.Deserialize(data, v)
+		if err != nil {
+			return err
+		}
+		return f(ctx, id, v)
+	}
+}
+
+// Schedule persists the data to be fired at the due time. Scheduling with an existing id replaces the scheduled item,
+// which makes it safe to schedule again with the same id, e.g. from a saga that handles the same event twice.
+func (s *Scheduler) Schedule(ctx context.Context, id string, due time.Time, data interface{}) error {
+	t := scheduledType(reflect.TypeOf(data))
+	if _, ok := s.handlers[t]; !ok {
+		return fmt.Errorf("%w: %s", ErrScheduledTypeNotHandled, t)
+	}
+	b, err := s.Encoder.Serialize(data)
+	if err != nil {
+		return err
+	}
+	return s.store.Schedule(ctx, ScheduledItem{ID: id, Due: due.UTC(), Type: t, Data: b})
+}
+
+// ScheduleAfter persists the data to be fired after the duration from now
+func (s *Scheduler) ScheduleAfter(ctx context.Context, id string, d time.Duration, data interface{}) error {
+	return s.Schedule(ctx, id, s.clock().Now().Add(d), data)
+}
+
+// Cancel removes the scheduled item
+func (s *Scheduler) Cancel(ctx context.Context, id string) error {
+	return s.store.Remove(ctx, id)
+}
+
+// Run fires due items until the context is cancelled or a handler returns an error
+func (s *Scheduler) Run(ctx context.Context) error {
+	for {
+		fired, err := s.RunOnce(ctx)
+		if err != nil {
+			return err
+		}
+		// continue direct if there could be more due items
+		if fired > 0 {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-s.clock().After(s.Pace):
+		}
+	}
+}
+
+// RunOnce fires one batch of due items and returns the number of fired items. If a handler returns an error the
+// items before it are removed and the rest is kept in the schedule store to be fired in a later run.
+func (s *Scheduler) RunOnce(ctx context.Context) (int, error) {
+	items, err := s.store.Due(ctx, s.clock().Now(), s.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+	for i, item := range items {
+		f, ok := s.handlers[item.Type]
+		if !ok {
+			return i, fmt.Errorf("%w: %s", ErrScheduledTypeNotHandled, item.Type)
+		}
+		err = f(ctx, item.ID, item.Data)
+		if err != nil {
+			return i, err
+		}
+		// the handler could have rescheduled the item under the same id
+		err = s.store.RemoveIfDue(ctx, item.ID, item.Due)
+		if err != nil && !errors.Is(err, ErrScheduledItemNotFound) {
+			return i, err
+		}
+	}
+	return len(items), nil
+}
+
+// clock returns the scheduler clock or the global clock if not set
+func (s *Scheduler) clock() Clock {
+	if s.Clock != nil {
+		return s.Clock
+	}
+	return clock
+}
+
+// scheduledType returns the name of the data type
+func scheduledType(t reflect.Type) string {
+	if t == nil {
+		return ""
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Name()
+}
@@ -0,0 +1,144 @@
+package eventsourcing_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/eventsourcingtest"
+	schedule "github.com/hallgren/eventsourcing/schedulestore/memory"
+)
+
+type ExpireReservation struct {
+	ReservationID string
+}
+
+func TestScheduler(t *testing.T) {
+	clock := eventsourcingtest.NewFakeClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	s := eventsourcing.NewScheduler(schedule.Create())
+	s.Clock = clock
+
+	expired := make([]string, 0)
+	eventsourcing.OnScheduled(s, func(ctx context.Context, id string, data *ExpireReservation) error {
+		expired = append(expired, data.ReservationID)
+		return nil
+	})
+
+	ctx := context.Background()
+	err := s.ScheduleAfter(ctx, "expire-1", time.Minute*15, ExpireReservation{ReservationID: "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = s.ScheduleAfter(ctx, "expire-2", time.Minute*30, &ExpireReservation{ReservationID: "2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fired, err := s.RunOnce(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fired != 0 {
+		t.Fatalf("expected no fired items got %d", fired)
+	}
+
+	clock.Advance(time.Minute * 15)
+	fired, err = s.RunOnce(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fired != 1 || expired[0] != "1" {
+		t.Fatalf("expected reservation 1 to expire got %v", expired)
+	}
+
+	// the second reservation is paid before it expires
+	err = s.Cancel(ctx, "expire-2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(time.Hour)
+	fired, err = s.RunOnce(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fired != 0 {
+		t.Fatalf("expected no fired items got %d", fired)
+	}
+}
+
+func TestSchedulerReschedule(t *testing.T) {
+	clock := eventsourcingtest.NewFakeClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	s := eventsourcing.NewScheduler(schedule.Create())
+	s.Clock = clock
+
+	// the handler reschedules the item under the same id until it has fired three times
+	calls := 0
+	eventsourcing.OnScheduled(s, func(ctx context.Context, id string, data *ExpireReservation) error {
+		calls++
+		if calls < 3 {
+			return s.ScheduleAfter(ctx, id, time.Minute, data)
+		}
+		return nil
+	})
+
+	ctx := context.Background()
+	err := s.ScheduleAfter(ctx, "remind", time.Minute, ExpireReservation{ReservationID: "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 4; i++ {
+		clock.Advance(time.Minute)
+		_, err = s.RunOnce(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if calls != 3 {
+		t.Fatalf("expected the rescheduled item to fire three times got %d", calls)
+	}
+}
+
+func TestSchedulerAtLeastOnce(t *testing.T) {
+	clock := eventsourcingtest.NewFakeClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	s := eventsourcing.NewScheduler(schedule.Create())
+	s.Clock = clock
+
+	ErrHandler := errors.New("handler error")
+	calls := 0
+	eventsourcing.OnScheduled(s, func(ctx context.Context, id string, data *ExpireReservation) error {
+		calls++
+		if calls == 1 {
+			return ErrHandler
+		}
+		return nil
+	})
+
+	ctx := context.Background()
+	err := s.Schedule(ctx, "expire-1", clock.Now(), ExpireReservation{ReservationID: "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = s.RunOnce(ctx)
+	if !errors.Is(err, ErrHandler) {
+		t.Fatalf("expected handler error got %v", err)
+	}
+
+	// the failed item is fired again
+	fired, err := s.RunOnce(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fired != 1 || calls != 2 {
+		t.Fatalf("expected the item to be fired again got %d fired and %d calls", fired, calls)
+	}
+}
+
+func TestScheduleNotHandledType(t *testing.T) {
+	s := eventsourcing.NewScheduler(schedule.Create())
+	err := s.ScheduleAfter(context.Background(), "1", time.Minute, ExpireReservation{})
+	if !errors.Is(err, eventsourcing.ErrScheduledTypeNotHandled) {
+		t.Fatalf("expected ErrScheduledTypeNotHandled got %v", err)
+	}
+}
@@ -0,0 +1,27 @@
+package eventsourcing
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrScheduledItemNotFound returned when the item is not in the schedule store
+var ErrScheduledItemNotFound = errors.New("scheduled item not found")
+
+// ScheduledItem is an event or command that is fired at the due time
+type ScheduledItem struct {
+	ID   string    // ID of the item, scheduling an item with an existing id replaces it
+	Due  time.Time // Due is the time when the item is fired
+	Type string    // Type is the name of the data type
+	Data []byte    // Data is the serialized event or command
+}
+
+// ScheduleStore expose the methods a schedule store must uphold
+type ScheduleStore interface {
+	Schedule(ctx context.Context, item ScheduledItem) error
+	Due(ctx context.Context, now time.Time, limit int) ([]ScheduledItem, error)
+	Remove(ctx context.Context, id string) error
+	// RemoveIfDue removes the item only if it's due at the due time, an item rescheduled to another time is kept
+	RemoveIfDue(ctx context.Context, id string, due time.Time) error
+}
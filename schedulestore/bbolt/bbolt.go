@@ -0,0 +1,156 @@
+package bbolt
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/hallgren/eventsourcing"
+	"go.etcd.io/bbolt"
+)
+
+const (
+	scheduledBucketName = "scheduled"
+	// the due bucket is an index of the items ordered by due time
+	dueBucketName = "due"
+)
+
+// BBolt is the schedule store handler
+type BBolt struct {
+	db *bbolt.DB
+}
+
+// MustOpenBBolt opens the schedule store found in the given file. If the file is not found it will be created and
+// initialized. Will panic if it has problems persisting the changes to the filesystem.
+func MustOpenBBolt(dbFile string) *BBolt {
+	db, err := bbolt.Open(dbFile, 0600, &bbolt.Options{
+		Timeout: 1 * time.Second,
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	// Ensure that we have the buckets to store the scheduled items
+	err = db.Update(func(tx *bbolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists([]byte(scheduledBucketName)); err != nil {
+			return errors.New("could not create scheduled bucket")
+		}
+		if _, err := tx.CreateBucketIfNotExists([]byte(dueBucketName)); err != nil {
+			return errors.New("could not create due bucket")
+		}
+		return nil
+	})
+	if err != nil {
+		panic(err)
+	}
+	return &BBolt{
+		db: db,
+	}
+}
+
+// Schedule persists the item, an item with the same id is replaced
+func (b *BBolt) Schedule(ctx context.Context, item eventsourcing.ScheduledItem) error {
+	value, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+	return b.db.Update(func(tx *bbolt.Tx) error {
+		err := remove(tx, item.ID)
+		if err != nil && !errors.Is(err, eventsourcing.ErrScheduledItemNotFound) {
+			return err
+		}
+		err = tx.Bucket([]byte(scheduledBucketName)).Put([]byte(item.ID), value)
+		if err != nil {
+			return err
+		}
+		return tx.Bucket([]byte(dueBucketName)).Put(dueKey(item), []byte(item.ID))
+	})
+}
+
+// Due returns the items with a due time before or equal to now in due order
+func (b *BBolt) Due(ctx context.Context, now time.Time, limit int) ([]eventsourcing.ScheduledItem, error) {
+	items := make([]eventsourcing.ScheduledItem, 0)
+	err := b.db.View(func(tx *bbolt.Tx) error {
+		scheduled := tx.Bucket([]byte(scheduledBucketName))
+		end := itob(uint64(now.UnixNano()))
+		cursor := tx.Bucket([]byte(dueBucketName)).Cursor()
+		for k, id := cursor.First(); k != nil && len(items) < limit; k, id = cursor.Next() {
+			if bytes.Compare(k[:8], end) > 0 {
+				break
+			}
+			var item eventsourcing.ScheduledItem
+			err := json.Unmarshal(scheduled.Get(id), &item)
+			if err != nil {
+				return err
+			}
+			items = append(items, item)
+		}
+		return nil
+	})
+	return items, err
+}
+
+// Remove deletes the item
+func (b *BBolt) Remove(ctx context.Context, id string) error {
+	return b.db.Update(func(tx *bbolt.Tx) error {
+		return remove(tx, id)
+	})
+}
+
+// RemoveIfDue deletes the item if it's due at the due time
+func (b *BBolt) RemoveIfDue(ctx context.Context, id string, due time.Time) error {
+	return b.db.Update(func(tx *bbolt.Tx) error {
+		value := tx.Bucket([]byte(scheduledBucketName)).Get([]byte(id))
+		if value == nil {
+			return eventsourcing.ErrScheduledItemNotFound
+		}
+		var item eventsourcing.ScheduledItem
+		err := json.Unmarshal(value, &item)
+		if err != nil {
+			return err
+		}
+		if !item.Due.Equal(due) {
+			return eventsourcing.ErrScheduledItemNotFound
+		}
+		return remove(tx, id)
+	})
+}
+
+// Close closes the underlying database
+func (b *BBolt) Close() error {
+	return b.db.Close()
+}
+
+// remove deletes the item and its due index
+func remove(tx *bbolt.Tx, id string) error {
+	scheduled := tx.Bucket([]byte(scheduledBucketName))
+	value := scheduled.Get([]byte(id))
+	if value == nil {
+		return eventsourcing.ErrScheduledItemNotFound
+	}
+	var item eventsourcing.ScheduledItem
+	err := json.Unmarshal(value, &item)
+	if err != nil {
+		return err
+	}
+	err = tx.Bucket([]byte(dueBucketName)).Delete(dueKey(item))
+	if err != nil {
+		return err
+	}
+	return scheduled.Delete([]byte(id))
+}
+
+// dueKey orders the items by due time, the id makes the key unique
+func dueKey(item eventsourcing.ScheduledItem) []byte {
+	return append(itob(uint64(item.Due.UnixNano())), []byte(item.ID)...)
+}
+
+// itob returns an 8-byte big endian representation of v.
+func itob(v uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, v)
+	return b
+}
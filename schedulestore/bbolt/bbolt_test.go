@@ -0,0 +1,22 @@
+package bbolt_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/eventsourcingtest"
+	"github.com/hallgren/eventsourcing/schedulestore/bbolt"
+)
+
+func TestSuite(t *testing.T) {
+	f := func() (eventsourcing.ScheduleStore, func(), error) {
+		dbFile := "schedule.db"
+		ss := bbolt.MustOpenBBolt(dbFile)
+		return ss, func() {
+			ss.Close()
+			os.Remove(dbFile)
+		}, nil
+	}
+	eventsourcingtest.TestScheduleStore(t, f)
+}
@@ -0,0 +1,80 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hallgren/eventsourcing"
+)
+
+type Memory struct {
+	items map[string]eventsourcing.ScheduledItem
+	lock  sync.Mutex
+}
+
+// Create in memory schedule store
+func Create() *Memory {
+	return &Memory{
+		items: make(map[string]eventsourcing.ScheduledItem),
+	}
+}
+
+func (m *Memory) Close() {
+
+}
+
+// Schedule stores the item, an item with the same id is replaced
+func (m *Memory) Schedule(ctx context.Context, item eventsourcing.ScheduledItem) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.items[item.ID] = item
+	return nil
+}
+
+// Due returns the items with a due time before or equal to now in due order
+func (m *Memory) Due(ctx context.Context, now time.Time, limit int) ([]eventsourcing.ScheduledItem, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	items := make([]eventsourcing.ScheduledItem, 0)
+	for _, item := range m.items {
+		if !item.Due.After(now) {
+			items = append(items, item)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Due.Before(items[j].Due)
+	})
+	if len(items) > limit {
+		items = items[:limit]
+	}
+	return items, nil
+}
+
+// Remove deletes the item
+func (m *Memory) Remove(ctx context.Context, id string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if _, ok := m.items[id]; !ok {
+		return eventsourcing.ErrScheduledItemNotFound
+	}
+	delete(m.items, id)
+	return nil
+}
+
+// RemoveIfDue deletes the item if it's due at the due time
+func (m *Memory) RemoveIfDue(ctx context.Context, id string, due time.Time) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	item, ok := m.items[id]
+	if !ok || !item.Due.Equal(due) {
+		return eventsourcing.ErrScheduledItemNotFound
+	}
+	delete(m.items, id)
+	return nil
+}
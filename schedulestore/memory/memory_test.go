@@ -0,0 +1,17 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/eventsourcingtest"
+	"github.com/hallgren/eventsourcing/schedulestore/memory"
+)
+
+func TestSuite(t *testing.T) {
+	f := func() (eventsourcing.ScheduleStore, func(), error) {
+		ss := memory.Create()
+		return ss, func() { ss.Close() }, nil
+	}
+	eventsourcingtest.TestScheduleStore(t, f)
+}
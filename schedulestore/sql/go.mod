@@ -0,0 +1,10 @@
+module github.com/hallgren/eventsourcing/schedulestore/sql
+
+go 1.13
+
+require (
+	github.com/hallgren/eventsourcing v0.6.0
+	github.com/mattn/go-sqlite3 v1.14.22
+)
+
+replace github.com/hallgren/eventsourcing => ../..
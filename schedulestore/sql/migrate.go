@@ -0,0 +1,37 @@
+package sql
+
+import "context"
+
+const createTable = `create table scheduled (id VARCHAR(255) NOT NULL PRIMARY KEY, due BIGINT NOT NULL, type VARCHAR(255), data BLOB);`
+
+// Migrate the database
+func (s *SQL) Migrate() error {
+	sqlStmt := []string{
+		createTable,
+		`create index due on scheduled (due);`,
+	}
+	return s.migrate(sqlStmt)
+}
+
+func (s *SQL) migrate(stm []string) error {
+	tx, err := s.db.BeginTx(context.Background(), nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// check if the migration is already done
+	rows, err := tx.Query(`Select count(*) from scheduled`)
+	if err == nil {
+		rows.Close()
+		return nil
+	}
+
+	for _, b := range stm {
+		_, err := tx.Exec(b)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
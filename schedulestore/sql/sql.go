@@ -0,0 +1,100 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hallgren/eventsourcing"
+)
+
+type SQL struct {
+	db *sql.DB
+}
+
+// Open connection to database
+func Open(db *sql.DB) *SQL {
+	return &SQL{
+		db: db,
+	}
+}
+
+// Close the connection
+func (s *SQL) Close() {
+	s.db.Close()
+}
+
+// Schedule persists the item, an item with the same id is replaced
+func (s *SQL) Schedule(ctx context.Context, item eventsourcing.ScheduledItem) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.New(fmt.Sprintf("could not start a write transaction, %v", err))
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM scheduled where id=$1`, item.ID)
+	if err != nil {
+		return err
+	}
+	// the due time is stored as unix nano to make it comparable in the database
+	_, err = tx.ExecContext(ctx, `INSERT INTO scheduled (id, due, type, data) VALUES ($1, $2, $3, $4)`, item.ID, item.Due.UnixNano(), item.Type, item.Data)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Due returns the items with a due time before or equal to now in due order
+func (s *SQL) Due(ctx context.Context, now time.Time, limit int) ([]eventsourcing.ScheduledItem, error) {
+	selectStm := `Select id, due, type, data from scheduled where due <= $1 order by due asc LIMIT $2`
+	rows, err := s.db.QueryContext(ctx, selectStm, now.UnixNano(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]eventsourcing.ScheduledItem, 0)
+	for rows.Next() {
+		var item eventsourcing.ScheduledItem
+		var due int64
+		err = rows.Scan(&item.ID, &due, &item.Type, &item.Data)
+		if err != nil {
+			return nil, err
+		}
+		item.Due = time.Unix(0, due).UTC()
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// Remove deletes the item
+func (s *SQL) Remove(ctx context.Context, id string) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM scheduled where id=$1`, id)
+	if err != nil {
+		return err
+	}
+	return removed(res)
+}
+
+// RemoveIfDue deletes the item if it's due at the due time
+func (s *SQL) RemoveIfDue(ctx context.Context, id string, due time.Time) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM scheduled where id=$1 and due=$2`, id, due.UnixNano())
+	if err != nil {
+		return err
+	}
+	return removed(res)
+}
+
+// removed returns ErrScheduledItemNotFound if the delete did not remove a row
+func removed(res sql.Result) error {
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return eventsourcing.ErrScheduledItemNotFound
+	}
+	return nil
+}
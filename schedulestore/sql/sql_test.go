@@ -0,0 +1,53 @@
+package sql_test
+
+import (
+	sqldriver "database/sql"
+	"testing"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/eventsourcingtest"
+	"github.com/hallgren/eventsourcing/schedulestore/sql"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestSuite(t *testing.T) {
+	f := func() (eventsourcing.ScheduleStore, func(), error) {
+		return schedulestore()
+	}
+	eventsourcingtest.TestScheduleStore(t, f)
+}
+
+func TestMultipleMigrate(t *testing.T) {
+	ss, close, err := schedulestore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer close()
+	err = ss.Migrate()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func schedulestore() (*sql.SQL, func(), error) {
+	db, err := sqldriver.Open("sqlite3", "file::memory:?cache=shared")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	db.SetMaxOpenConns(1)
+	err = db.Ping()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	store := sql.Open(db)
+	err = store.Migrate()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return store, func() {
+		store.Close()
+	}, nil
+}
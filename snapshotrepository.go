@@ -113,6 +113,24 @@ func (s *SnapshotRepository) Save(a aggregate) error {
 	return s.SaveSnapshot(a)
 }
 
+// SaveWith saves the aggregate events and snapshot via the save funcs, e.g. to save them in a database transaction
+// controlled by the caller. Call the returned publish func when the transaction is committed.
+func (s *SnapshotRepository) SaveWith(a aggregate, eventsF func(events []core.Event) error, snapshotF func(snapshot core.Snapshot) error) (publish func(), err error) {
+	publish, err = s.eventRepository.SaveWith(a, eventsF)
+	if err != nil {
+		return nil, err
+	}
+	snapshot, err := s.snapshot(a)
+	if err != nil {
+		return nil, err
+	}
+	err = snapshotF(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	return publish, nil
+}
+
 // SaveSnapshot will only store the snapshot and will return an error if there are events that are not stored
 func (s *SnapshotRepository) SaveSnapshot(a aggregate) error {
 	root := a.Root()
@@ -120,6 +138,16 @@ func (s *SnapshotRepository) SaveSnapshot(a aggregate) error {
 		return ErrUnsavedEvents
 	}
 
+	snapshot, err := s.snapshot(a)
+	if err != nil {
+		return err
+	}
+	return s.snapshotStore.Save(snapshot)
+}
+
+// snapshot builds the snapshot of the aggregate including events that are saved but not yet published
+func (s *SnapshotRepository) snapshot(a aggregate) (core.Snapshot, error) {
+	root := a.Root()
 	state := []byte{}
 	var err error
 	// Does the aggregate have specific snapshot handling
@@ -127,23 +155,24 @@ func (s *SnapshotRepository) SaveSnapshot(a aggregate) error {
 	if ok {
 		state, err = sa.SerializeSnapshot(s.Encoder.Serialize)
 		if err != nil {
-			return err
+			return core.Snapshot{}, err
 		}
 	} else {
 		state, err = s.Encoder.Serialize(a)
 		if err != nil {
-			return err
+			return core.Snapshot{}, err
 		}
 	}
 
-	snapshot := core.Snapshot{
+	globalVersion := root.GlobalVersion()
+	if len(root.aggregateEvents) > 0 {
+		globalVersion = root.aggregateEvents[len(root.aggregateEvents)-1].GlobalVersion()
+	}
+	return core.Snapshot{
 		ID:            root.ID(),
 		Type:          aggregateType(a),
 		Version:       core.Version(root.Version()),
-		GlobalVersion: core.Version(root.GlobalVersion()),
+		GlobalVersion: core.Version(globalVersion),
 		State:         state,
-	}
-
-	err = s.snapshotStore.Save(snapshot)
-	return err
+	}, nil
 }
@@ -170,3 +170,31 @@ func TestSnapshotNoneExported(t *testing.T) {
 		t.Fatalf("exported value differed %s %s", snap.Exported, snap2.Exported)
 	}
 }
+
+func TestSnapshotSaveWith(t *testing.T) {
+	es := memory.Create()
+	ss := snap.Create()
+	eventrepo := eventsourcing.NewEventRepository(es)
+	eventrepo.Register(&Person{})
+	snapshotrepo := eventsourcing.NewSnapshotRepository(ss, eventrepo)
+
+	person, err := CreatePerson("kalle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	person.GrowOlder()
+
+	publish, err := snapshotrepo.SaveWith(person, es.Save, ss.Save)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publish()
+
+	snapshot, err := ss.Get(context.Background(), person.ID(), "Person")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snapshot.Version != 2 || snapshot.GlobalVersion != 2 {
+		t.Fatalf("expected snapshot version and global version 2 got %d and %d", snapshot.Version, snapshot.GlobalVersion)
+	}
+}
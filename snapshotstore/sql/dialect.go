@@ -0,0 +1,99 @@
+package sql
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Dialect renders the SQL that differs between databases, i.e. the DDL and the query placeholders
+type Dialect struct {
+	name        string
+	placeholder func(n int) string
+	createTable string
+	indexSchema bool // the schema is set on the index name instead of the table name when creating an index
+}
+
+// SQLite dialect
+func SQLite() Dialect {
+	return Dialect{
+		name:        "sqlite",
+		placeholder: func(n int) string { return "?" },
+		createTable: `create table %s (id VARCHAR NOT NULL, type VARCHAR, version INTEGER, global_version INTEGER, state BLOB);`,
+		indexSchema: true,
+	}
+}
+
+// PostgreSQL dialect
+func PostgreSQL() Dialect {
+	return Dialect{
+		name:        "postgres",
+		placeholder: func(n int) string { return "$" + strconv.Itoa(n) },
+		createTable: `create table %s (id VARCHAR NOT NULL, "type" VARCHAR, version INTEGER, global_version INTEGER, state bytea);`,
+	}
+}
+
+// MySQL dialect
+func MySQL() Dialect {
+	return Dialect{
+		name:        "mysql",
+		placeholder: func(n int) string { return "?" },
+		createTable: `create table %s (id VARCHAR(255) NOT NULL, type VARCHAR(255), version INTEGER, global_version INTEGER, state BLOB);`,
+	}
+}
+
+// Name of the dialect
+func (d Dialect) Name() string {
+	return d.name
+}
+
+// Rebind replaces the ? placeholders in the query with the placeholders of the dialect
+func (d Dialect) Rebind(query string) string {
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString(d.placeholder(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// CreateTable returns the statement that creates the snapshots table
+func (d Dialect) CreateTable(table string) string {
+	return fmt.Sprintf(d.createTable, table)
+}
+
+// CreateIndex returns the statement that creates an index on the columns of the table in the schema. The schema is
+// optional.
+func (d Dialect) CreateIndex(unique bool, schema, index, table, columns string) string {
+	statement := "create index"
+	if unique {
+		statement = "create unique index"
+	}
+	if schema != "" {
+		if d.indexSchema {
+			index = schema + "." + index
+		} else {
+			table = schema + "." + table
+		}
+	}
+	return fmt.Sprintf("%s %s on %s (%s);", statement, index, table, columns)
+}
+
+// dialectFromDriver returns the dialect based on the driver type, SQLite if the driver is unknown. It's used when
+// no dialect is set, to keep the stores opened before the WithDialect option working.
+func dialectFromDriver(driver driver.Driver) Dialect {
+	switch reflect.TypeOf(driver).String() {
+	case "*pq.Driver", "*stdlib.Driver":
+		return PostgreSQL()
+	case "*mysql.MySQLDriver":
+		return MySQL()
+	}
+	return SQLite()
+}
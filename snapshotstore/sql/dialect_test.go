@@ -0,0 +1,83 @@
+package sql_test
+
+import (
+	sqldriver "database/sql"
+	"strings"
+	"testing"
+
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/core/testsuite"
+	"github.com/hallgren/eventsourcing/snapshotstore/sql"
+)
+
+func TestRebind(t *testing.T) {
+	query := `UPDATE snapshots set state=?, version=?, global_version=? where id=? AND type=?`
+	tests := []struct {
+		dialect  sql.Dialect
+		expected string
+	}{
+		{sql.SQLite(), `UPDATE snapshots set state=?, version=?, global_version=? where id=? AND type=?`},
+		{sql.MySQL(), `UPDATE snapshots set state=?, version=?, global_version=? where id=? AND type=?`},
+		{sql.PostgreSQL(), `UPDATE snapshots set state=$1, version=$2, global_version=$3 where id=$4 AND type=$5`},
+	}
+	for _, test := range tests {
+		t.Run(test.dialect.Name(), func(t *testing.T) {
+			if got := test.dialect.Rebind(query); got != test.expected {
+				t.Fatalf("expected %s got %s", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestCreateTable(t *testing.T) {
+	tests := []struct {
+		dialect sql.Dialect
+		state   string
+	}{
+		{sql.SQLite(), "state BLOB"},
+		{sql.MySQL(), "id VARCHAR(255) NOT NULL"},
+		{sql.PostgreSQL(), "state bytea"},
+	}
+	for _, test := range tests {
+		t.Run(test.dialect.Name(), func(t *testing.T) {
+			if !strings.Contains(test.dialect.CreateTable("snapshots"), test.state) {
+				t.Fatalf("expected %s in %s", test.state, test.dialect.CreateTable("snapshots"))
+			}
+		})
+	}
+}
+
+func TestCreateIndex(t *testing.T) {
+	tests := []struct {
+		dialect  sql.Dialect
+		expected string
+	}{
+		{sql.SQLite(), `create unique index ctx.id_type on snapshots (id, type);`},
+		{sql.MySQL(), `create unique index id_type on ctx.snapshots (id, type);`},
+		{sql.PostgreSQL(), `create unique index id_type on ctx.snapshots (id, type);`},
+	}
+	for _, test := range tests {
+		t.Run(test.dialect.Name(), func(t *testing.T) {
+			if got := test.dialect.CreateIndex(true, "ctx", "id_type", "snapshots", "id, type"); got != test.expected {
+				t.Fatalf("expected %s got %s", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestSuiteSQLiteDialect(t *testing.T) {
+	f := func() (core.SnapshotStore, func(), error) {
+		db, err := sqldriver.Open("sqlite3", "file::memory:?cache=shared")
+		if err != nil {
+			return nil, nil, err
+		}
+		db.SetMaxOpenConns(1)
+		ss := sql.Open(db, sql.WithDialect(sql.SQLite()))
+		err = ss.Migrate()
+		if err != nil {
+			return nil, nil, err
+		}
+		return ss, ss.Close, nil
+	}
+	testsuite.TestSnapshotStore(t, f)
+}
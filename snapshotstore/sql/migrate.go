@@ -1,37 +1,136 @@
 package sql
 
-import "context"
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+)
 
-const createTable = `create table snapshots (id VARCHAR NOT NULL, type VARCHAR, version INTEGER, global_version INTEGER, state BLOB);`
+// migrationsTable records the applied migrations
+const migrationsTable = "snapshots_migrations"
 
-// Migrate the database
+// Migration is a schema change that is applied once to the database
+type Migration struct {
+	Version     int
+	Description string
+	Statements  []string
+}
+
+// Migrations returns the migrations in the order they are applied. New migrations are added last with the next version.
+func (s *SQL) Migrations() []Migration {
+	return []Migration{
+		{
+			Version:     1,
+			Description: "create snapshots table",
+			Statements: []string{
+				s.dialect.CreateTable(s.table("snapshots")),
+				s.createIndex(true, "id_type", "snapshots", "id, type"),
+			},
+		},
+	}
+}
+
+// Migrate applies the migrations that are not already applied to the database
 func (s *SQL) Migrate() error {
-	sqlStmt := []string{
-		createTable,
-		`create unique index id_type on snapshots (id, type);`,
+	_, err := s.migrate(false)
+	return err
+}
+
+// MigrateDryRun returns the migrations that Migrate would apply without applying them
+func (s *SQL) MigrateDryRun() ([]Migration, error) {
+	return s.migrate(true)
+}
+
+func (s *SQL) migrate(dryRun bool) ([]Migration, error) {
+	applied, recorded, err := s.appliedMigrations()
+	if err != nil {
+		return nil, err
+	}
+	pending := make([]Migration, 0)
+	for _, m := range s.Migrations() {
+		if !applied[m.Version] {
+			pending = append(pending, m)
+		}
+	}
+	if dryRun {
+		return pending, nil
+	}
+
+	_, err = s.db.Exec(`create table if not exists ` + s.table(migrationsTable) + ` (version INTEGER PRIMARY KEY, description VARCHAR(255), applied_at VARCHAR(255));`)
+	if err != nil {
+		return nil, err
+	}
+	// record the migrations that was applied before the migrations were recorded
+	for _, m := range s.Migrations() {
+		if applied[m.Version] && !recorded[m.Version] {
+			err = s.applyMigration(Migration{Version: m.Version, Description: m.Description})
+			if err != nil {
+				return nil, err
+			}
+		}
 	}
-	return s.migrate(sqlStmt)
+	for _, m := range pending {
+		err = s.applyMigration(m)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return pending, nil
 }
 
-func (s *SQL) migrate(stm []string) error {
-	tx, err := s.db.BeginTx(context.Background(), nil)
+// appliedMigrations returns the applied and the recorded migrations. A database migrated before the migrations
+// were recorded has the snapshots table but no recorded migrations, the first migration is then applied but not recorded.
+func (s *SQL) appliedMigrations() (map[int]bool, map[int]bool, error) {
+	applied := make(map[int]bool)
+	recorded := make(map[int]bool)
+	rows, err := s.db.Query(`Select version from ` + s.table(migrationsTable))
 	if err != nil {
-		return err
+		// the migrations table does not exist
+		rows, err := s.db.Query(`Select count(*) from ` + s.table("snapshots"))
+		if err == nil {
+			applied[1] = true
+			return applied, recorded, rows.Close()
+		}
+		return applied, recorded, nil
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var version int
+		err = rows.Scan(&version)
+		if err != nil {
+			return nil, nil, err
+		}
+		applied[version] = true
+		recorded[version] = true
 	}
-	defer tx.Rollback()
+	return applied, recorded, rows.Err()
+}
 
-	// check if the migration is already done
-	rows, err := tx.Query(`Select count(*) from snapshots`)
-	if err == nil {
-		rows.Close()
-		return nil
+// applyMigration executes the migration statements and records it in one transaction
+func (s *SQL) applyMigration(m Migration) error {
+	tx, err := s.db.BeginTx(context.Background(), nil)
+	if err != nil {
+		return err
 	}
+	defer func(tx *sql.Tx) {
+		err := tx.Rollback()
+		if err != nil && err != sql.ErrTxDone {
+			log.Printf("could not rollback transaction %v", err)
+		}
+	}(tx)
 
-	for _, b := range stm {
-		_, err := tx.Exec(b)
+	for _, statement := range m.Statements {
+		_, err := tx.Exec(statement)
 		if err != nil {
 			return err
 		}
 	}
+	insert := s.dialect.Rebind(`Insert into ` + s.table(migrationsTable) + ` (version, description, applied_at) values (?, ?, ?)`)
+	_, err = tx.Exec(insert, m.Version, m.Description, time.Now().UTC().Format(time.RFC3339))
+	if err != nil {
+		return err
+	}
 	return tx.Commit()
 }
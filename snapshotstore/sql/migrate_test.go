@@ -0,0 +1,74 @@
+package sql_test
+
+import (
+	sqldriver "database/sql"
+	"testing"
+
+	"github.com/hallgren/eventsourcing/snapshotstore/sql"
+)
+
+func openDB(t *testing.T, name string) *sqldriver.DB {
+	db, err := sqldriver.Open("sqlite3", "file:"+name+"?mode=memory&cache=shared")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestMigrateDryRun(t *testing.T) {
+	ss := sql.Open(openDB(t, "dryrun"))
+
+	pending, err := ss.MigrateDryRun()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != len(ss.Migrations()) {
+		t.Fatalf("expected all migrations to be pending got %d", len(pending))
+	}
+
+	err = ss.Migrate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pending, err = ss.MigrateDryRun()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 0 {
+		t.Fatalf("expected no pending migrations got %d", len(pending))
+	}
+}
+
+func TestMigrateExistingDatabase(t *testing.T) {
+	db := openDB(t, "existing")
+	// the snapshots table created before the migrations were recorded
+	for _, statement := range sql.Open(db).Migrations()[0].Statements {
+		_, err := db.Exec(statement)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ss := sql.Open(db)
+	pending, err := ss.MigrateDryRun()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 0 {
+		t.Fatalf("expected no pending migrations got %d", len(pending))
+	}
+	err = ss.Migrate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var recorded int
+	err = db.QueryRow(`Select count(*) from snapshots_migrations`).Scan(&recorded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recorded != 1 {
+		t.Fatalf("expected one recorded migration got %d", recorded)
+	}
+}
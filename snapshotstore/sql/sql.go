@@ -10,14 +10,62 @@ import (
 )
 
 type SQL struct {
-	db *sql.DB
+	db      *sql.DB
+	dialect Dialect
+	schema  string
+	prefix  string
 }
 
-// Open connection to database
-func Open(db *sql.DB) *SQL {
-	return &SQL{
+// Option configures the SQL snapshot store
+type Option func(s *SQL)
+
+// WithDialect sets the database dialect and is the supported way to choose it. If not set the dialect is based on
+// the type of the database driver, with SQLite as fallback, kept for backward compatibility.
+func WithDialect(dialect Dialect) Option {
+	return func(s *SQL) {
+		s.dialect = dialect
+	}
+}
+
+// WithSchema places the tables in the database schema
+func WithSchema(schema string) Option {
+	return func(s *SQL) {
+		s.schema = schema
+	}
+}
+
+// WithTablePrefix adds the prefix to the table and index names, e.g. to host several snapshot stores in one database
+func WithTablePrefix(prefix string) Option {
+	return func(s *SQL) {
+		s.prefix = prefix
+	}
+}
+
+// Open connection to database. Set the dialect of the database with the WithDialect option.
+func Open(db *sql.DB, options ...Option) *SQL {
+	s := &SQL{
 		db: db,
 	}
+	for _, option := range options {
+		option(s)
+	}
+	if s.dialect.name == "" {
+		s.dialect = dialectFromDriver(db.Driver())
+	}
+	return s
+}
+
+// table returns the table name with the prefix and schema
+func (s *SQL) table(name string) string {
+	if s.schema != "" {
+		return s.schema + "." + s.prefix + name
+	}
+	return s.prefix + name
+}
+
+// createIndex returns the statement that creates the index on the table with the prefix and schema
+func (s *SQL) createIndex(unique bool, index, table, columns string) string {
+	return s.dialect.CreateIndex(unique, s.schema, s.prefix+index, s.prefix+table, columns)
 }
 
 // Close the connection
@@ -27,34 +75,43 @@ func (s *SQL) Close() {
 
 // Save persists the snapshot
 func (s *SQL) Save(snapshot core.Snapshot) error {
-	tx, err := s.db.BeginTx(context.Background(), nil)
+	ctx := context.Background()
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return errors.New(fmt.Sprintf("could not start a write transaction, %v", err))
 	}
 	defer tx.Rollback()
 
-	statement := `SELECT id from snapshots where id=$1 AND type=$2 LIMIT 1`
+	err = s.save(ctx, tx, snapshot)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// SaveTx persists the snapshot in the transaction, e.g. to save it atomically with the aggregate events
+func (s *SQL) SaveTx(ctx context.Context, tx *sql.Tx, snapshot core.Snapshot) error {
+	return s.save(ctx, tx, snapshot)
+}
+
+// save inserts or updates the snapshot in the transaction
+func (s *SQL) save(ctx context.Context, tx *sql.Tx, snapshot core.Snapshot) error {
+	statement := s.dialect.Rebind(`SELECT id from ` + s.table("snapshots") + ` where id=? AND type=? LIMIT 1`)
 	var id string
-	err = tx.QueryRow(statement, snapshot.ID, snapshot.Type).Scan(&id)
+	err := tx.QueryRowContext(ctx, statement, snapshot.ID, snapshot.Type).Scan(&id)
 	if err != nil && err != sql.ErrNoRows {
 		return err
 	}
 	if err == sql.ErrNoRows {
 		// insert
-		statement = `INSERT INTO snapshots (state, id, type, version, global_version) VALUES ($1, $2, $3, $4, $5)`
-		_, err = tx.Exec(statement, string(snapshot.State), snapshot.ID, snapshot.Type, snapshot.Version, snapshot.GlobalVersion)
-		if err != nil {
-			return err
-		}
+		statement = s.dialect.Rebind(`INSERT INTO ` + s.table("snapshots") + ` (state, id, type, version, global_version) VALUES (?, ?, ?, ?, ?)`)
+		_, err = tx.ExecContext(ctx, statement, string(snapshot.State), snapshot.ID, snapshot.Type, snapshot.Version, snapshot.GlobalVersion)
 	} else {
 		// update
-		statement = `UPDATE snapshots set state=$1, version=$2, global_version=$3 where id=$4 AND type=$5`
-		_, err = tx.Exec(statement, string(snapshot.State), snapshot.Version, snapshot.GlobalVersion, snapshot.ID, snapshot.Type)
-		if err != nil {
-			return err
-		}
+		statement = s.dialect.Rebind(`UPDATE ` + s.table("snapshots") + ` set state=?, version=?, global_version=? where id=? AND type=?`)
+		_, err = tx.ExecContext(ctx, statement, string(snapshot.State), snapshot.Version, snapshot.GlobalVersion, snapshot.ID, snapshot.Type)
 	}
-	return tx.Commit()
+	return err
 }
 
 // Get return the snapshot data from the database
@@ -63,7 +120,7 @@ func (s *SQL) Get(ctx context.Context, aggregateID, aggregateType string) (core.
 	var version core.Version
 	var state []byte
 
-	selectStm := `Select version, global_version, state from snapshots where id=? and type=?`
+	selectStm := s.dialect.Rebind(`Select version, global_version, state from ` + s.table("snapshots") + ` where id=? and type=?`)
 	row := s.db.QueryRow(selectStm, aggregateID, aggregateType)
 	if row.Err() != nil {
 		return core.Snapshot{}, row.Err()
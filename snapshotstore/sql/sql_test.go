@@ -1,7 +1,9 @@
 package sql_test
 
 import (
+	"context"
 	sqldriver "database/sql"
+	"errors"
 	"testing"
 
 	"github.com/hallgren/eventsourcing/core"
@@ -51,3 +53,95 @@ func snapshotstore() (*sql.SQL, func(), error) {
 		store.Close()
 	}, nil
 }
+
+func TestSuiteTablePrefix(t *testing.T) {
+	f := func() (core.SnapshotStore, func(), error) {
+		db, err := sqldriver.Open("sqlite3", "file::memory:?cache=shared")
+		if err != nil {
+			return nil, nil, err
+		}
+		db.SetMaxOpenConns(1)
+		store := sql.Open(db, sql.WithTablePrefix("orders_"))
+		err = store.Migrate()
+		if err != nil {
+			return nil, nil, err
+		}
+		return store, store.Close, nil
+	}
+	testsuite.TestSnapshotStore(t, f)
+}
+
+func TestSuiteSchema(t *testing.T) {
+	f := func() (core.SnapshotStore, func(), error) {
+		db, err := sqldriver.Open("sqlite3", "file::memory:?cache=shared")
+		if err != nil {
+			return nil, nil, err
+		}
+		// the attached database is the schema in sqlite
+		db.SetMaxOpenConns(1)
+		_, err = db.Exec(`attach database 'file:ctx?mode=memory&cache=shared' as ctx`)
+		if err != nil {
+			return nil, nil, err
+		}
+		store := sql.Open(db, sql.WithSchema("ctx"))
+		err = store.Migrate()
+		if err != nil {
+			return nil, nil, err
+		}
+		return store, store.Close, nil
+	}
+	testsuite.TestSnapshotStore(t, f)
+}
+
+func TestSaveTx(t *testing.T) {
+	db, err := sqldriver.Open("sqlite3", "file::memory:?cache=shared")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	store := sql.Open(db)
+	defer store.Close()
+	err = store.Migrate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	snapshot := core.Snapshot{ID: "123", Type: "Person", Version: 1, GlobalVersion: 1, State: []byte("{}")}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = store.SaveTx(ctx, tx, snapshot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tx.Rollback()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = store.Get(ctx, snapshot.ID, snapshot.Type)
+	if !errors.Is(err, core.ErrSnapshotNotFound) {
+		t.Fatalf("expected no snapshot after rollback got %v", err)
+	}
+
+	tx, err = db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = store.SaveTx(ctx, tx, snapshot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tx.Commit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := store.Get(ctx, snapshot.ID, snapshot.Type)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Version != snapshot.Version {
+		t.Fatalf("expected version %d got %d", snapshot.Version, s.Version)
+	}
+}
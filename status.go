@@ -0,0 +1,97 @@
+package eventsourcing
+
+import (
+	"time"
+)
+
+// ProjectionState is the state of a projection
+type ProjectionState string
+
+const (
+	// StateStopped the projection is not running
+	StateStopped ProjectionState = "stopped"
+	// StateRunning the projection is running
+	StateRunning ProjectionState = "running"
+	// StateFailed the projection is not running and its last run returned an error
+	StateFailed ProjectionState = "failed"
+)
+
+// ProjectionStatus is a snapshot of the projection state
+type ProjectionStatus struct {
+	Name            string
+	Version         int
+	State           ProjectionState
+	Position        Version   // Position is the global version of the last handled event
+	Head            Version   // Head is the global version of the last event in the event store, zero if unknown
+	Lag             uint64    // Lag is the number of events between the position and the head
+	LastError       error     // LastError is the error from the last run, nil if it succeeded
+	LastEventAt     time.Time // LastEventAt is when the projection last handled an event
+	EventsPerSecond float64   // EventsPerSecond is the rate events were handled in the last run that handled events
+}
+
+// Status returns the projection status. The head is the global version of the last event in the event store and is
+// used to calculate the lag, pass zero if it's unknown. It's safe to call while the projection is running.
+func (p *Projection) Status(head Version) ProjectionStatus {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	state := StateStopped
+	if p.running.Load() {
+		state = StateRunning
+	} else if p.lastError != nil {
+		state = StateFailed
+	}
+
+	var lag uint64
+	if head > Version(p.position) {
+		lag = uint64(head) - uint64(p.position)
+	}
+
+	return ProjectionStatus{
+		Name:            p.Name,
+		Version:         p.Version,
+		State:           state,
+		Position:        Version(p.position),
+		Head:            head,
+		Lag:             lag,
+		LastError:       p.lastError,
+		LastEventAt:     p.lastEventAt,
+		EventsPerSecond: p.eventsPerSecond,
+	}
+}
+
+// updateStatus updates the status after a run that started at the position
+func (p *Projection) updateStatus(start time.Time, position Version, err error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	now := p.clock().Now()
+	// a run without events keeps the rate of the last run that handled events
+	if Version(p.position) > position {
+		p.lastEventAt = now
+		if elapsed := now.Sub(start).Seconds(); elapsed > 0 {
+			p.eventsPerSecond = float64(Version(p.position)-position) / elapsed
+		}
+	}
+	p.lastError = err
+}
+
+// Status returns the status of the projections in the group. If the group Head func is set it's used to calculate
+// the lag of each projection. It's safe to call while the group is running.
+func (g *Group) Status() ([]ProjectionStatus, error) {
+	var head Version
+	if g.Head != nil {
+		h, err := g.Head()
+		if err != nil {
+			return nil, err
+		}
+		head = Version(h)
+	}
+
+	projections := g.members()
+	status := make([]ProjectionStatus, len(projections))
+	for i, p := range projections {
+		status[i] = p.Status(head)
+	}
+	return status, nil
+}
@@ -0,0 +1,179 @@
+package eventsourcing_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hallgren/eventsourcing"
+	checkpoint "github.com/hallgren/eventsourcing/checkpointstore/memory"
+	"github.com/hallgren/eventsourcing/eventstore/memory"
+)
+
+func TestProjectionStatus(t *testing.T) {
+	// setup
+	es := memory.Create()
+	register := eventsourcing.NewRegister()
+	register.Register(&Person{})
+
+	err := createPersonEvent(es, "kalle", 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := eventsourcing.NewProjectionHandler(register, eventsourcing.EncoderJSON{})
+	proj := p.Projection(es.All(0, 2), func(event eventsourcing.Event) error {
+		return nil
+	})
+	proj.Name = "person"
+
+	status := proj.Status(5)
+	if status.State != eventsourcing.StateStopped {
+		t.Fatalf("expected state stopped got %s", status.State)
+	}
+	if status.Lag != 5 {
+		t.Fatalf("expected lag 5 got %d", status.Lag)
+	}
+
+	proj.RunOnce()
+	status = proj.Status(5)
+	if status.Name != "person" || status.Position != 2 || status.Lag != 3 {
+		t.Fatalf("expected position 2 and lag 3 got %+v", status)
+	}
+	if status.LastEventAt.IsZero() {
+		t.Fatal("expected last event time to be set")
+	}
+	if status.EventsPerSecond <= 0 {
+		t.Fatalf("expected events per second to be positive got %f", status.EventsPerSecond)
+	}
+
+	proj.RunToEnd(context.Background())
+	status = proj.Status(5)
+	if status.Position != 5 || status.Lag != 0 {
+		t.Fatalf("expected position 5 and lag 0 got %+v", status)
+	}
+	// the last run of RunToEnd finds no events and keeps the rate
+	if status.EventsPerSecond <= 0 {
+		t.Fatalf("expected events per second to be positive after run to end got %f", status.EventsPerSecond)
+	}
+}
+
+func TestProjectionStatusCheckpoint(t *testing.T) {
+	// setup
+	es := memory.Create()
+	register := eventsourcing.NewRegister()
+	register.Register(&Person{})
+	checkpoints := checkpoint.Create()
+
+	err := createPersonEvent(es, "kalle", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the projection has handled all events before a restart
+	err = checkpoints.Save(context.Background(), "person", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handled := 0
+	p := eventsourcing.NewProjectionHandler(register, eventsourcing.EncoderJSON{})
+	proj := p.ProjectionFrom(es.AllFrom(1), func(event eventsourcing.Event) error {
+		handled++
+		return nil
+	})
+	proj.Name = "person"
+	proj.Checkpoint = checkpoints
+
+	proj.RunOnce()
+	status := proj.Status(3)
+	if handled != 0 || status.Position != 3 {
+		t.Fatalf("expected no handled events and position 3 got %d handled and %+v", handled, status)
+	}
+	// the restored checkpoint is not counted as events handled in the run
+	if !status.LastEventAt.IsZero() || status.EventsPerSecond != 0 {
+		t.Fatalf("expected no last event time and rate got %+v", status)
+	}
+}
+
+func TestProjectionStatusFailed(t *testing.T) {
+	// setup
+	es := memory.Create()
+	register := eventsourcing.NewRegister()
+	register.Register(&Person{})
+
+	err := createPersonEvent(es, "kalle", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ErrApplication := errors.New("application error")
+	p := eventsourcing.NewProjectionHandler(register, eventsourcing.EncoderJSON{})
+	proj := p.Projection(es.All(0, 1), func(event eventsourcing.Event) error {
+		return ErrApplication
+	})
+
+	proj.RunOnce()
+	status := proj.Status(0)
+	if status.State != eventsourcing.StateFailed {
+		t.Fatalf("expected state failed got %s", status.State)
+	}
+	if !errors.Is(status.LastError, ErrApplication) {
+		t.Fatalf("expected application error got %v", status.LastError)
+	}
+}
+
+func TestGroupStatus(t *testing.T) {
+	// setup
+	es := memory.Create()
+	register := eventsourcing.NewRegister()
+	register.Register(&Person{})
+
+	err := createPersonEvent(es, "kalle", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := eventsourcing.NewProjectionHandler(register, eventsourcing.EncoderJSON{})
+	p1 := p.Projection(es.All(0, 1), func(event eventsourcing.Event) error { return nil })
+	p2 := p.Projection(es.All(0, 1), func(event eventsourcing.Event) error {
+		time.Sleep(time.Millisecond)
+		return nil
+	})
+
+	g := p.Group(p1, p2)
+	g.Head = es.Head
+	g.Pace = time.Millisecond
+	g.Start()
+	defer g.Stop()
+
+	// call status concurrently with the running projections until they have reached the end of the event stream
+	var status []eventsourcing.ProjectionStatus
+	timeout := time.After(time.Second)
+	for {
+		status, err = g.Status()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if status[0].Position == 3 && status[1].Position == 3 {
+			break
+		}
+		select {
+		case <-timeout:
+			t.Fatalf("projections did not reach the end of the event stream %+v", status)
+		case <-time.After(time.Millisecond):
+		}
+	}
+
+	if len(status) != 2 {
+		t.Fatalf("expected status for two projections got %d", len(status))
+	}
+	for _, s := range status {
+		if s.State != eventsourcing.StateRunning {
+			t.Fatalf("expected state running got %s", s.State)
+		}
+		if s.Head != 3 || s.Position != 3 || s.Lag != 0 {
+			t.Fatalf("expected head 3, position 3 and lag 0 got %+v", s)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package eventsourcing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// RestartStrategy returns the wait time before a failed projection is restarted and false if it should not be restarted.
+// Restarts is the number of restarts since the projection last made progress.
+type RestartStrategy func(restarts int) (time.Duration, bool)
+
+// RestartNever never restarts a failed projection
+func RestartNever() RestartStrategy {
+	return func(restarts int) (time.Duration, bool) {
+		return 0, false
+	}
+}
+
+// RestartAlways restarts a failed projection directly
+func RestartAlways() RestartStrategy {
+	return func(restarts int) (time.Duration, bool) {
+		return 0, true
+	}
+}
+
+// RestartWithBackoff restarts a failed projection after a wait time that starts at backoff and is doubled for
+// each restart until it reaches maxBackoff. If maxRestarts is larger than zero the projection is not restarted
+// after maxRestarts restarts in a row without progress.
+func RestartWithBackoff(backoff, maxBackoff time.Duration, maxRestarts int) RestartStrategy {
+	policy := RetryPolicy{Backoff: backoff, MaxBackoff: maxBackoff}
+	return func(restarts int) (time.Duration, bool) {
+		if maxRestarts > 0 && restarts >= maxRestarts {
+			return 0, false
+		}
+		return policy.wait(restarts), true
+	}
+}
+
+// ProjectionError is sent on the group error channel when a projection fails
+type ProjectionError struct {
+	Name       string // Name of the failing projection
+	Version    int    // Version of the failing projection
+	Err        error  // Err is the error that made the projection fail
+	Restarting bool   // Restarting is true if the projection will be restarted
+}
+
+func (e *ProjectionError) Error() string {
+	return fmt.Sprintf("projection %s (version %d) failed: %v", e.Name, e.Version, e.Err)
+}
+
+func (e *ProjectionError) Unwrap() error {
+	return e.Err
+}
+
+// supervise runs the projection until the context is cancelled. When the projection fails the error is sent on
+// the error channel and the projection is restarted according to its restart strategy. Claimed is true if the
+// projection already is set as running by the group.
+func (g *Group) supervise(ctx context.Context, p *Projection, claimed bool) {
+	restarts := 0
+	for {
+		var err error
+		position := p.Position()
+		if claimed || p.running.CompareAndSwap(false, true) {
+			err = p.run(ctx, g.Pace)
+		} else {
+			err = ErrProjectionAlreadyRunning
+		}
+		claimed = false
+		if errors.Is(err, context.Canceled) {
+			return
+		}
+		// start over the restart count if the projection has made progress
+		if p.Position() > position {
+			restarts = 0
+		}
+
+		var wait time.Duration
+		var restart bool
+		if p.Restart != nil {
+			wait, restart = p.Restart(restarts)
+		}
+		select {
+		case g.ErrChan <- &ProjectionError{Name: p.Name, Version: p.Version, Err: err, Restarting: restart}:
+		case <-ctx.Done():
+			return
+		}
+		if !restart {
+			return
+		}
+		restarts++
+		select {
+		case <-ctx.Done():
+			return
+		case <-p.clock().After(wait):
+		}
+	}
+}
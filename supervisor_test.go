@@ -0,0 +1,154 @@
+package eventsourcing_test
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/eventstore/memory"
+)
+
+func TestGroupRestart(t *testing.T) {
+	// setup
+	es := memory.Create()
+	register := eventsourcing.NewRegister()
+	register.Register(&Person{})
+
+	err := createPersonEvent(es, "kalle", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ErrApplication := errors.New("application error")
+	var calls atomic.Int32
+	p := eventsourcing.NewProjectionHandler(register, eventsourcing.EncoderJSON{})
+	proj := p.ProjectionFrom(es.AllFrom(1), func(event eventsourcing.Event) error {
+		// fail the first two calls
+		if calls.Add(1) < 3 {
+			return ErrApplication
+		}
+		return nil
+	})
+	proj.Name = "person"
+	proj.Restart = eventsourcing.RestartWithBackoff(time.Millisecond, time.Millisecond*10, 0)
+
+	g := p.Group(proj)
+	g.Pace = time.Millisecond * 10
+	g.Start()
+	defer g.Stop()
+
+	for i := 0; i < 2; i++ {
+		select {
+		case err = <-g.ErrChan:
+		case <-time.After(time.Second):
+			t.Fatal("test timed out")
+		}
+		var projectionErr *eventsourcing.ProjectionError
+		if !errors.As(err, &projectionErr) {
+			t.Fatalf("expected projection error got %v", err)
+		}
+		if projectionErr.Name != "person" || !projectionErr.Restarting || !errors.Is(err, ErrApplication) {
+			t.Fatalf("wrong projection error %v", projectionErr)
+		}
+	}
+
+	// the restarted projection reaches the end of the event stream
+	timeout := time.After(time.Second)
+	for proj.Position() != 1 {
+		select {
+		case <-timeout:
+			t.Fatalf("expected position 1 got %d", proj.Position())
+		case <-time.After(time.Millisecond):
+		}
+	}
+}
+
+func TestGroupRestartMax(t *testing.T) {
+	// setup
+	es := memory.Create()
+	register := eventsourcing.NewRegister()
+	register.Register(&Person{})
+
+	err := createPersonEvent(es, "kalle", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := eventsourcing.NewProjectionHandler(register, eventsourcing.EncoderJSON{})
+	proj := p.ProjectionFrom(es.AllFrom(1), func(event eventsourcing.Event) error {
+		return errors.New("application error")
+	})
+	proj.Restart = eventsourcing.RestartWithBackoff(time.Millisecond, 0, 1)
+
+	g := p.Group(proj)
+	g.Start()
+	defer g.Stop()
+
+	restarting := []bool{true, false}
+	for _, r := range restarting {
+		select {
+		case err = <-g.ErrChan:
+		case <-time.After(time.Second):
+			t.Fatal("test timed out")
+		}
+		var projectionErr *eventsourcing.ProjectionError
+		if !errors.As(err, &projectionErr) || projectionErr.Restarting != r {
+			t.Fatalf("expected restarting %t got %v", r, err)
+		}
+	}
+}
+
+func TestGroupAddRemove(t *testing.T) {
+	// setup
+	es := memory.Create()
+	register := eventsourcing.NewRegister()
+	register.Register(&Person{})
+
+	err := createPersonEvent(es, "kalle", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var first, second atomic.Int32
+	p := eventsourcing.NewProjectionHandler(register, eventsourcing.EncoderJSON{})
+	proj1 := p.ProjectionFrom(es.AllFrom(1), func(event eventsourcing.Event) error {
+		first.Add(1)
+		return nil
+	})
+	proj2 := p.ProjectionFrom(es.AllFrom(1), func(event eventsourcing.Event) error {
+		second.Add(1)
+		return nil
+	})
+
+	g := p.Group(proj1)
+	g.Pace = time.Millisecond * 10
+	g.Start()
+	defer g.Stop()
+
+	// a projection added to a running group is started
+	g.Add(proj2)
+	g.TriggerSync()
+	if first.Load() != 2 || second.Load() != 2 {
+		t.Fatalf("expected both projections to handle 2 events got %d and %d", first.Load(), second.Load())
+	}
+
+	// a removed projection is stopped and no longer handles events
+	g.Remove(proj1)
+	err = createPersonEvent(es, "anka", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.TriggerSync()
+	if first.Load() != 2 || second.Load() != 3 {
+		t.Fatalf("expected 2 and 3 handled events got %d and %d", first.Load(), second.Load())
+	}
+	status, err := g.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(status) != 1 {
+		t.Fatalf("expected one projection in the group got %d", len(status))
+	}
+}